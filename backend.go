@@ -0,0 +1,247 @@
+package sendbit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+)
+
+// The list and recipient operations served by every SendGrid API
+// generation. The client validates the arguments, opens the spans and
+// wraps the errors, so a backend only talks to its endpoints.
+type backend interface {
+	// Creates a list
+	createList(ctx context.Context, name string) error
+	// Removes a list
+	deleteList(ctx context.Context, name string) error
+	// Renames a list
+	renameList(ctx context.Context, name, newName string) error
+	// Returns the lists with particular names in the order of the names.
+	// The names that no list has are skipped.
+	lists(ctx context.Context, names []string) ([]List, error)
+	// Returns a page of the lists along with the cursor of the next one
+	listPage(ctx context.Context, cursor string, size int) ([]List, string, error)
+	// Validates the recipients before any of their batches is added
+	checkRecipients(ctx context.Context, recipients []Recipient) error
+	// Adds a batch of recipients and returns the number of the inserted ones
+	// along with the job ingesting them when it is asynchronous
+	addRecipients(ctx context.Context, list string, recipients []Recipient) (int, *Job, error)
+	// Replaces an existing recipient with its new name and custom fields and
+	// returns the job updating it when it is asynchronous
+	updateRecipient(ctx context.Context, list string, previous, recipient *Recipient) (*Job, error)
+	// Removes a batch of emails and returns the number of the removed ones
+	deleteRecipients(ctx context.Context, list string, emails []string) (int, error)
+	// Returns every recipient of a list or only the one with a particular email
+	recipients(ctx context.Context, list, email string) ([]Recipient, error)
+	// Returns a page of the recipients of a list along with the cursor of
+	// the next one
+	recipientPage(ctx context.Context, list, cursor string, size int) ([]Recipient, string, error)
+	// Returns the number of the recipients of a list
+	recipientCount(ctx context.Context, list string) (uint64, error)
+}
+
+func (client *Client) backend() backend {
+	if client.API == MarketingAPI {
+		return &marketingBackend{client: client}
+	}
+	return &legacyBackend{client: client}
+}
+
+// The v2 newsletter API backend
+type legacyBackend struct {
+	client *Client
+}
+
+func (backend *legacyBackend) createList(ctx context.Context, name string) error {
+	data := url.Values{}
+	data.Add("list", name)
+	_, err := backend.client.post(ctx, "/newsletter/lists/add.json", data)
+	return err
+}
+
+func (backend *legacyBackend) deleteList(ctx context.Context, name string) error {
+	data := url.Values{}
+	data.Add("list", name)
+	_, err := backend.client.post(ctx, "/newsletter/lists/delete.json", data)
+	return err
+}
+
+func (backend *legacyBackend) renameList(ctx context.Context, name, newName string) error {
+	data := url.Values{}
+	data.Add("list", name)
+	data.Add("newlist", newName)
+	_, err := backend.client.post(ctx, "/newsletter/lists/edit.json", data)
+	return err
+}
+
+// The v2 API filters the lists by a single name, so every name is
+// requested separately
+func (backend *legacyBackend) lists(ctx context.Context, names []string) ([]List, error) {
+	lists := []List{}
+	for _, name := range names {
+		found, err := backend.getLists(ctx, name)
+		if IsListNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, list := range found {
+			if list.Name == name {
+				lists = append(lists, list)
+			}
+		}
+	}
+	return lists, nil
+}
+
+// Returns every list or only the one with a particular name
+func (backend *legacyBackend) getLists(ctx context.Context, name string) ([]List, error) {
+	var data url.Values
+	if name != "" {
+		data = url.Values{}
+		data.Add("list", name)
+	}
+
+	response, err := backend.client.post(ctx, "/newsletter/lists/get.json", data)
+	if err != nil {
+		return nil, err
+	}
+
+	var lists []List
+	if err := json.NewDecoder(response).Decode(&lists); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return lists, nil
+}
+
+// The v2 API does not page the lists, so they come in a single page
+func (backend *legacyBackend) listPage(ctx context.Context, cursor string, size int) ([]List, string, error) {
+	lists, err := backend.getLists(ctx, "")
+	return lists, "", err
+}
+
+func (backend *legacyBackend) checkRecipients(ctx context.Context, recipients []Recipient) error {
+	return nil
+}
+
+func (backend *legacyBackend) addRecipients(ctx context.Context, list string, recipients []Recipient) (int, *Job, error) {
+	// A single recipient is sent as data and a batch as data[]
+	key := "data[]"
+	if len(recipients) == 1 {
+		key = "data"
+	}
+
+	data := url.Values{}
+	data.Add("list", list)
+	for _, recipient := range recipients {
+		body, err := json.Marshal(recipient)
+		if err != nil {
+			return 0, nil, err
+		}
+		data.Add(key, string(body))
+	}
+
+	response, err := backend.client.post(ctx, "/newsletter/lists/email/add.json", data)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var stats struct {
+		AffectedRows int `json:"inserted"`
+	}
+
+	if err := json.NewDecoder(response).Decode(&stats); err != nil {
+		return 0, nil, err
+	}
+	return stats.AffectedRows, nil, nil
+}
+
+// The v2 API cannot change a recipient, so it is removed and added back.
+// The previous recipient is restored when it cannot be added back.
+func (backend *legacyBackend) updateRecipient(ctx context.Context, list string, previous, recipient *Recipient) (*Job, error) {
+	if _, err := backend.deleteRecipients(ctx, list, []string{previous.Email}); err != nil {
+		return nil, err
+	}
+
+	inserted, _, err := backend.addRecipients(ctx, list, []Recipient{*recipient})
+	if err == nil && inserted == 0 {
+		err = errors.New("The recipient was not added back.")
+	}
+	if err != nil {
+		if _, _, rollback := backend.addRecipients(ctx, list, []Recipient{*previous}); rollback != nil {
+			return nil, fmt.Errorf("%w The rollback failed: %s", err, rollback)
+		}
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+func (backend *legacyBackend) deleteRecipients(ctx context.Context, list string, emails []string) (int, error) {
+	data := url.Values{}
+	data.Add("list", list)
+	data["email[]"] = emails
+
+	response, err := backend.client.post(ctx, "/newsletter/lists/email/delete.json", data)
+	if err != nil {
+		return 0, err
+	}
+
+	var stats struct {
+		AffectedRows int `json:"removed"`
+	}
+
+	if err := json.NewDecoder(response).Decode(&stats); err != nil {
+		return 0, err
+	}
+	return stats.AffectedRows, nil
+}
+
+func (backend *legacyBackend) recipients(ctx context.Context, list, email string) ([]Recipient, error) {
+	data := url.Values{}
+	data.Add("list", list)
+	if email != "" {
+		data.Add("email", email)
+	}
+
+	response, err := backend.client.post(ctx, "/newsletter/lists/email/get.json", data)
+	if err != nil {
+		return nil, err
+	}
+
+	var recipients []Recipient
+	if err := json.NewDecoder(response).Decode(&recipients); err != nil {
+		return nil, err
+	}
+	return recipients, nil
+}
+
+// The v2 API does not page the recipients, so they come in a single page
+func (backend *legacyBackend) recipientPage(ctx context.Context, list, cursor string, size int) ([]Recipient, string, error) {
+	recipients, err := backend.recipients(ctx, list, "")
+	return recipients, "", err
+}
+
+func (backend *legacyBackend) recipientCount(ctx context.Context, list string) (uint64, error) {
+	data := url.Values{}
+	data.Add("list", list)
+
+	response, err := backend.client.post(ctx, "/newsletter/lists/email/count.json", data)
+	if err != nil {
+		return 0, err
+	}
+
+	var stats struct {
+		Count uint64 `json:"count"`
+	}
+
+	if err := json.NewDecoder(response).Decode(&stats); err != nil {
+		return 0, err
+	}
+	return stats.Count, nil
+}
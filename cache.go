@@ -0,0 +1,226 @@
+package sendbit
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// The number of the results kept when MemoryCache.Size is zero
+const DefaultCacheSize = 1000
+
+// The time a result is kept for when MemoryCache.TTL is zero
+const DefaultCacheTTL = time.Minute
+
+// Stores the results of the read calls of a client. The values are shared
+// between the callers and must not be changed. A cache must be safe for
+// concurrent use and must not be shared by clients of different accounts.
+type Cache interface {
+	// Returns the value stored under a key
+	Get(key string) (interface{}, bool)
+	// Stores a value under a key
+	Set(key string, value interface{})
+	// Removes the value stored under a key
+	Delete(key string)
+}
+
+// Enables the read cache of the client. A MemoryCache with the default
+// size and TTL is used when cache is nil. The concurrent identical reads
+// of the client and of its WithContext copies share a single request.
+func WithCache(cache Cache) Option {
+	return func(client *Client) {
+		if cache == nil {
+			cache = &MemoryCache{}
+		}
+		client.Cache = cache
+		client.cacheState = &cacheState{generations: map[string]uint64{}}
+	}
+}
+
+// An in-memory Cache that evicts the least recently used results and
+// expires them after a TTL. The zero value is ready to use.
+type MemoryCache struct {
+	// Size - The maximum number of the kept results. DefaultCacheSize is
+	// used when it is zero.
+	Size int
+	// TTL - The time a result is kept for. DefaultCacheTTL is used when
+	// it is zero.
+	TTL time.Duration
+
+	mutex   sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+type cacheEntry struct {
+	key     string
+	value   interface{}
+	expires time.Time
+}
+
+func (cache *MemoryCache) Get(key string) (interface{}, bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	element, ok := cache.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := element.Value.(*cacheEntry)
+	if time.Now().After(entry.expires) {
+		cache.remove(element)
+		return nil, false
+	}
+
+	cache.order.MoveToFront(element)
+	return entry.value, true
+}
+
+func (cache *MemoryCache) Set(key string, value interface{}) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if cache.entries == nil {
+		cache.entries = map[string]*list.Element{}
+		cache.order = list.New()
+	}
+
+	ttl := cache.TTL
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+	entry := &cacheEntry{key: key, value: value, expires: time.Now().Add(ttl)}
+
+	if element, ok := cache.entries[key]; ok {
+		element.Value = entry
+		cache.order.MoveToFront(element)
+		return
+	}
+	cache.entries[key] = cache.order.PushFront(entry)
+
+	size := cache.Size
+	if size <= 0 {
+		size = DefaultCacheSize
+	}
+	for cache.order.Len() > size {
+		cache.remove(cache.order.Back())
+	}
+}
+
+func (cache *MemoryCache) Delete(key string) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if element, ok := cache.entries[key]; ok {
+		cache.remove(element)
+	}
+}
+
+func (cache *MemoryCache) remove(element *list.Element) {
+	cache.order.Remove(element)
+	delete(cache.entries, element.Value.(*cacheEntry).key)
+}
+
+// The state of the reads served from the cache of a client, shared by
+// its WithContext copies
+type cacheState struct {
+	flights singleflight.Group
+
+	mutex sync.Mutex
+	// The number of the invalidations of every invalidated key
+	generations map[string]uint64
+}
+
+func (state *cacheState) generation(key string) uint64 {
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	return state.generations[key]
+}
+
+// Stores a value unless the key was invalidated since the value was read
+func (state *cacheState) set(cache Cache, key string, generation uint64, value interface{}) {
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	if state.generations[key] == generation {
+		cache.Set(key, value)
+	}
+}
+
+func (state *cacheState) invalidate(cache Cache, key string) {
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	state.generations[key]++
+	cache.Delete(key)
+}
+
+// Builds the cache key of a read call
+func cacheKey(method, argument string) string {
+	return method + "\x00" + argument
+}
+
+// Serves a read call from the cache. The concurrent identical reads
+// share a single request, which is not cancelled with the context of
+// any of them, and only the successful results are stored. The reads are
+// not shared when the cache is set without WithCache.
+func (client *Client) cached(ctx context.Context, key string, read func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	cache := client.Cache
+	if cache == nil {
+		return read(ctx)
+	}
+
+	if value, ok := cache.Get(key); ok {
+		return value, nil
+	}
+
+	state := client.cacheState
+	if state == nil {
+		value, err := read(ctx)
+		if err == nil {
+			cache.Set(key, value)
+		}
+		return value, err
+	}
+
+	// The reads started after an invalidation do not join the ones
+	// started before it
+	generation := state.generation(key)
+	flight := fmt.Sprintf("%d\x00%s", generation, key)
+	detached := context.WithoutCancel(ctx)
+
+	results := state.flights.DoChan(flight, func() (interface{}, error) {
+		value, err := read(detached)
+		if err == nil {
+			state.set(cache, key, generation, value)
+		}
+		return value, err
+	})
+
+	select {
+	case result := <-results:
+		return result.Val, result.Err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Removes the cached results of the read calls affected by a change. The
+// results of the reads in flight are not stored.
+func (client *Client) invalidate(keys ...string) {
+	cache := client.Cache
+	if cache == nil {
+		return
+	}
+
+	for _, key := range keys {
+		if client.cacheState == nil {
+			cache.Delete(key)
+			continue
+		}
+		client.cacheState.invalidate(cache, key)
+	}
+}
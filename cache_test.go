@@ -0,0 +1,221 @@
+package sendbit_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/svett/sendbit"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MemoryCache", func() {
+	It("evicts the least recently used values", func() {
+		cache := &MemoryCache{Size: 2}
+		cache.Set("a", 1)
+		cache.Set("b", 2)
+		_, ok := cache.Get("a")
+		Expect(ok).To(BeTrue())
+
+		cache.Set("c", 3)
+		_, ok = cache.Get("b")
+		Expect(ok).To(BeFalse())
+		value, _ := cache.Get("a")
+		Expect(value).To(Equal(1))
+		value, _ = cache.Get("c")
+		Expect(value).To(Equal(3))
+	})
+
+	It("expires the values", func() {
+		cache := &MemoryCache{TTL: 10 * time.Millisecond}
+		cache.Set("a", 1)
+		_, ok := cache.Get("a")
+		Expect(ok).To(BeTrue())
+		Eventually(func() bool {
+			_, ok := cache.Get("a")
+			return ok
+		}).Should(BeFalse())
+	})
+
+	It("deletes a value", func() {
+		cache := &MemoryCache{}
+		cache.Set("a", 1)
+		cache.Delete("a")
+		_, ok := cache.Get("a")
+		Expect(ok).To(BeFalse())
+	})
+})
+
+// A Cache of a type that is not comparable
+type mapCache map[string]interface{}
+
+func (cache mapCache) Get(key string) (interface{}, bool) {
+	value, ok := cache[key]
+	return value, ok
+}
+
+func (cache mapCache) Set(key string, value interface{}) {
+	cache[key] = value
+}
+
+func (cache mapCache) Delete(key string) {
+	delete(cache, key)
+}
+
+var _ = Describe("Cache", func() {
+	var (
+		fake   *FakeSendGrid
+		client *Client
+	)
+
+	requests := func(path string) int {
+		count := 0
+		for _, request := range fake.Requests {
+			if request == path {
+				count++
+			}
+		}
+		return count
+	}
+
+	BeforeEach(func() {
+		fake = NewFakeSendGrid()
+		fake.AddList("newsletter", Recipient{Email: "j.smith@example.com"})
+		client = fake.Client()
+		WithCache(nil)(client)
+	})
+
+	AfterEach(func() {
+		fake.Close()
+	})
+
+	It("serves the list from the cache", func() {
+		first, err := client.List("newsletter")
+		Expect(err).ToNot(HaveOccurred())
+		first.Name = "changed"
+
+		second, err := client.List("newsletter")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(second.Name).To(Equal("newsletter"))
+		Expect(requests("/newsletter/lists/get.json")).To(Equal(1))
+	})
+
+	It("invalidates the list when it is deleted or created", func() {
+		_, err := client.List("newsletter")
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(client.DeleteList("newsletter")).To(Succeed())
+		Expect(client.CreateList("newsletter")).To(Succeed())
+
+		_, err = client.List("newsletter")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(requests("/newsletter/lists/get.json")).To(Equal(2))
+	})
+
+	It("invalidates the recipient count when the recipients change", func() {
+		Expect(client.RecipientCount("newsletter")).To(Equal(uint64(1)))
+		Expect(client.RecipientCount("newsletter")).To(Equal(uint64(1)))
+		Expect(requests("/newsletter/lists/email/count.json")).To(Equal(1))
+
+		Expect(client.AddRecipient("newsletter", &Recipient{Email: "m.freeman@example.com"})).To(Succeed())
+		Expect(client.RecipientCount("newsletter")).To(Equal(uint64(2)))
+
+		Expect(client.DeleteRecipient("newsletter", "j.smith@example.com")).To(Succeed())
+		Expect(client.RecipientCount("newsletter")).To(Equal(uint64(1)))
+		Expect(requests("/newsletter/lists/email/count.json")).To(Equal(3))
+	})
+
+	Context("when the cache is not comparable", func() {
+		It("serves the list from the cache", func() {
+			WithCache(mapCache{})(client)
+			for index := 0; index < 2; index++ {
+				_, err := client.WithContext(context.Background()).List("newsletter")
+				Expect(err).ToNot(HaveOccurred())
+			}
+			Expect(requests("/newsletter/lists/get.json")).To(Equal(1))
+		})
+	})
+
+	Context("when the reads are concurrent", func() {
+		var (
+			server *httptest.Server
+			hits   int32
+		)
+
+		BeforeEach(func() {
+			atomic.StoreInt32(&hits, 0)
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/api/newsletter/lists/email/delete.json" {
+					w.Write([]byte(`{"removed": 1}`))
+					return
+				}
+				atomic.AddInt32(&hits, 1)
+				time.Sleep(50 * time.Millisecond)
+				w.Write([]byte(`{"count": 3}`))
+			}))
+			client.Host = server.URL
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("sends a single request", func() {
+			group := sync.WaitGroup{}
+			for index := 0; index < 10; index++ {
+				group.Add(1)
+				go func() {
+					defer GinkgoRecover()
+					defer group.Done()
+					Expect(client.RecipientCount("newsletter")).To(Equal(uint64(3)))
+				}()
+			}
+			group.Wait()
+			Expect(atomic.LoadInt32(&hits)).To(Equal(int32(1)))
+		})
+
+		It("does not store a read that started before an invalidation", func() {
+			done := make(chan struct{})
+			go func() {
+				defer GinkgoRecover()
+				defer close(done)
+				Expect(client.RecipientCount("newsletter")).To(Equal(uint64(3)))
+			}()
+
+			Eventually(func() int32 { return atomic.LoadInt32(&hits) }).Should(Equal(int32(1)))
+			Expect(client.DeleteRecipient("newsletter", "j.smith@example.com")).To(Succeed())
+			<-done
+
+			Expect(client.RecipientCount("newsletter")).To(Equal(uint64(3)))
+			Expect(atomic.LoadInt32(&hits)).To(Equal(int32(2)))
+		})
+
+		It("completes the shared read when a caller is cancelled", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancelled := make(chan error)
+			go func() {
+				_, err := client.WithContext(ctx).RecipientCount("newsletter")
+				cancelled <- err
+			}()
+
+			Eventually(func() int32 { return atomic.LoadInt32(&hits) }).Should(Equal(int32(1)))
+			count := make(chan uint64)
+			go func() {
+				defer GinkgoRecover()
+				value, err := client.RecipientCount("newsletter")
+				Expect(err).ToNot(HaveOccurred())
+				count <- value
+			}()
+
+			cancel()
+			Expect(<-cancelled).To(MatchError(ContainSubstring("context canceled")))
+			Expect(<-count).To(Equal(uint64(3)))
+			Expect(atomic.LoadInt32(&hits)).To(Equal(int32(1)))
+		})
+	})
+})
@@ -2,6 +2,7 @@ package sendbit
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -12,6 +13,26 @@ import (
 	"os"
 	"strings"
 	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// The SendGrid API host used when Client.Host is empty
+const DefaultHost = "https://api.sendgrid.com"
+
+// The concurrency used when Client.Concurrency is zero
+const DefaultConcurrency = 4
+
+// Selects the SendGrid API generation that a client talks to
+type API int
+
+const (
+	// The legacy v2 newsletter API authenticated by api_user and api_key
+	LegacyAPI API = iota
+	// The v3 Marketing Campaigns API authenticated by a bearer API key
+	MarketingAPI
 )
 
 type Response struct {
@@ -31,34 +52,106 @@ type Auth struct {
 // You should instanciate it in the following manner
 //
 // client := sendbit.NewClient("your_username", "your_password")
+//
+// The v3 Marketing Campaigns API is used when it is created with the
+// MarketingAPI option. Its API key is passed as a password:
+//
+// client := sendbit.NewClient("apikey", "your_api_key", sendbit.WithAPI(sendbit.MarketingAPI))
 type Client struct {
 	Auth *Auth
+	// API - The SendGrid API generation that serves the list and
+	// recipient calls. LegacyAPI is used by default.
+	API API
+	// Host - The SendGrid API host. DefaultHost is used when it is empty.
+	Host string
+	// TracerProvider - An OpenTelemetry provider used to open a span per
+	// client call. The global provider is used when it is nil.
+	TracerProvider trace.TracerProvider
+	// Validator - Validates the recipient email addresses before they are
+	// sent. A zero EmailValidator is used when it is nil.
+	Validator *EmailValidator
+	// Concurrency - The maximum number of concurrent requests sent by
+	// a call fanned out over many lists. DefaultConcurrency is used when
+	// it is zero.
+	Concurrency int
+	// SkipUnsubscribed - Skips the globally unsubscribed recipients
+	// instead of adding them to a list
+	SkipUnsubscribed bool
+	// Cache - Caches the results of List and RecipientCount. They are
+	// invalidated by the calls of the client that change the lists and
+	// their recipients. Nothing is cached when it is nil. It is set by
+	// WithCache.
+	Cache Cache
+
+	ctx context.Context
+	// The state of the reads served from Cache, shared by the WithContext
+	// copies of the client
+	cacheState *cacheState
+}
+
+// Configures a client created by NewClient
+type Option func(client *Client)
+
+// Selects the SendGrid API generation of the client
+func WithAPI(api API) Option {
+	return func(client *Client) {
+		client.API = api
+	}
 }
 
 // Creates a new client from Environment variables
 // SENDGRID_USER
 // SENDGRID_PASS
-func NewClientFromEnv() (*Client, error) {
+func NewClientFromEnv(options ...Option) (*Client, error) {
 	user := os.Getenv("SENDGRID_USER")
 	pass := os.Getenv("SENDGRID_PASS")
-	return NewClient(user, pass)
+	return NewClient(user, pass, options...)
 }
 
 // Creates a new instance of sendbit.Client for
 // concreted SendGrid Account
-func NewClient(username, password string) (*Client, error) {
+func NewClient(username, password string, options ...Option) (*Client, error) {
 	if username == "" {
 		return nil, errors.New("sendbit: Username argument cannot be empty.")
 	}
 	if password == "" {
 		return nil, errors.New("sendbit: Password argument cannot be empty.")
 	}
-	return &Client{
+	client := &Client{
 		Auth: &Auth{
 			Username: username,
 			Password: password,
 		},
-	}, nil
+	}
+	for _, option := range options {
+		option(client)
+	}
+	return client, nil
+}
+
+// Returns a shallow copy of the client whose calls are bound to ctx.
+// The spans and the requests sent by the copy are children of ctx.
+func (client *Client) WithContext(ctx context.Context) *Client {
+	if ctx == nil {
+		panic("sendbit: nil context")
+	}
+	clone := *client
+	clone.ctx = ctx
+	return &clone
+}
+
+func (client *Client) context() context.Context {
+	if client.ctx == nil {
+		return context.Background()
+	}
+	return client.ctx
+}
+
+func (client *Client) host() string {
+	if client.Host == "" {
+		return DefaultHost
+	}
+	return strings.TrimSuffix(client.Host, "/")
 }
 
 func (client *Client) authenticate(data url.Values) error {
@@ -73,7 +166,16 @@ func (client *Client) authenticate(data url.Values) error {
 	return nil
 }
 
-func (client *Client) post(path string, data url.Values) (io.Reader, error) {
+func (client *Client) post(ctx context.Context, path string, data url.Values) (io.Reader, error) {
+	response, err := client.send(ctx, path, data)
+	if err != nil {
+		failSpan(ctx, err)
+		return nil, err
+	}
+	return response, nil
+}
+
+func (client *Client) send(ctx context.Context, path string, data url.Values) (io.Reader, error) {
 	if data == nil {
 		data = url.Values{}
 	}
@@ -83,14 +185,91 @@ func (client *Client) post(path string, data url.Values) (io.Reader, error) {
 
 	path = strings.TrimPrefix(path, "/")
 	path = strings.TrimSuffix(path, "/")
-	host := fmt.Sprintf("https://api.sendgrid.com/api/%s", path)
+	path = fmt.Sprintf("/api/%s", path)
+	trace.SpanFromContext(ctx).SetAttributes(endpointKey.String(path))
 
-	request, err := http.NewRequest("POST", host, strings.NewReader(data.Encode()))
+	request, err := http.NewRequestWithContext(ctx, "POST", client.host()+path,
+		strings.NewReader(data.Encode()))
 	if err != nil {
 		return nil, err
 	}
 
 	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return client.do(ctx, request)
+}
+
+// Sends a JSON request to the v3 API. The path is relative to /v3 and
+// may contain a query. The value is encoded as the request body unless
+// it is nil.
+func (client *Client) request(ctx context.Context, method, path string, value interface{}) (io.Reader, error) {
+	response, err := client.sendJSON(ctx, method, path, value)
+	if err != nil {
+		failSpan(ctx, err)
+		return nil, err
+	}
+	return response, nil
+}
+
+// Sends a v3 request and decodes the response into out unless it is nil
+func (client *Client) call(ctx context.Context, method, path string, body, out interface{}) error {
+	response, err := client.request(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(response).Decode(out)
+}
+
+func (client *Client) sendJSON(ctx context.Context, method, path string, value interface{}) (io.Reader, error) {
+	if client.Auth == nil || client.Auth.Password == "" {
+		return nil, fmt.Errorf("The client credentails are missing or invalid.")
+	}
+
+	path = fmt.Sprintf("/v3/%s", strings.TrimPrefix(path, "/"))
+	trace.SpanFromContext(ctx).SetAttributes(endpointKey.String(strings.SplitN(path, "?", 2)[0]))
+
+	var body io.Reader
+	if value != nil {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, client.host()+path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	request.Header.Set("Authorization", "Bearer "+client.Auth.Password)
+	if value != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	return client.do(ctx, request)
+}
+
+// An error returned by the SendGrid API
+type responseError struct {
+	status  int
+	message string
+}
+
+func (err *responseError) Error() string {
+	return err.message
+}
+
+// Determines whether err is an API error with a particular status code
+func hasStatus(err error, status int) bool {
+	var response *responseError
+	return errors.As(err, &response) && response.status == status
+}
+
+func (client *Client) do(ctx context.Context, request *http.Request) (io.Reader, error) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(request.Header))
 	request.Header.Set("User-Agent", "sendbit/0.0.1;go")
 
 	httpClient := &http.Client{
@@ -103,6 +282,8 @@ func (client *Client) post(path string, data url.Values) (io.Reader, error) {
 		return nil, err
 	}
 	defer response.Body.Close()
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(statusCodeKey.Int(response.StatusCode))
 
 	body, err := ioutil.ReadAll(response.Body)
 	if err != nil {
@@ -111,16 +292,35 @@ func (client *Client) post(path string, data url.Values) (io.Reader, error) {
 
 	var message Response
 	if err := json.Unmarshal(body, &message); err == nil && message.Error != "" {
-		return nil, errors.New(message.Error)
+		span.SetAttributes(responseErrorKey.String(message.Error))
+		return nil, &responseError{status: response.StatusCode, message: message.Error}
+	}
+
+	var failure struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &failure); err == nil && len(failure.Errors) > 0 {
+		messages := make([]string, len(failure.Errors))
+		for index, item := range failure.Errors {
+			messages[index] = item.Message
+		}
+		text := strings.Join(messages, "; ")
+		span.SetAttributes(responseErrorKey.String(text))
+		return nil, &responseError{status: response.StatusCode, message: text}
 	}
 
-	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("The response status code is %d", response.StatusCode)
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return nil, &responseError{
+			status:  response.StatusCode,
+			message: fmt.Sprintf("The response status code is %d", response.StatusCode),
+		}
 	}
 
 	return bytes.NewReader(body), nil
 }
 
 func (client *Client) errorf(method string, err error) error {
-	return fmt.Errorf("sendbit: client.%s error: %s", method, err)
+	return fmt.Errorf("sendbit: client.%s error: %w", method, err)
 }
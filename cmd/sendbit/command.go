@@ -0,0 +1,398 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/svett/sendbit"
+)
+
+// The exit codes mapped from the error kinds
+const (
+	exitOK = iota
+	exitFailure
+	exitUsage
+	exitNotExist
+	exitExist
+	exitInvalid
+	exitCredentials
+)
+
+var errUsage = errors.New("usage")
+
+// Creates the client the commands are run with
+type clientFactory func() (*sendbit.Client, error)
+
+// Executes a single invocation of the command
+type command struct {
+	stdout  io.Writer
+	stderr  io.Writer
+	connect clientFactory
+	output  string
+	client  *sendbit.Client
+}
+
+func run(args []string, stdout, stderr io.Writer, connect clientFactory) int {
+	cmd := &command{stdout: stdout, stderr: stderr, connect: connect}
+
+	flags := cmd.flags("sendbit")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	err := cmd.dispatch(flags.Args())
+	if errors.Is(err, errUsage) {
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
+	if err != nil {
+		// The client errors are already prefixed with the package name
+		message := err.Error()
+		if !strings.HasPrefix(message, "sendbit: ") {
+			message = "sendbit: " + message
+		}
+		fmt.Fprintln(stderr, message)
+	}
+	return exitCode(err)
+}
+
+const usage = `usage: sendbit [--output table|json|csv] <command>
+
+commands:
+  lists ls
+  lists create <list>
+  lists rm <list>
+  lists rename <list> <new-name>
+  recipients ls <list>
+  recipients add [--name name] [--field key=value]... <list> <email>
+  recipients rm <list> <email>...
+  recipients count <list>
+  recipients import [--map column=field]... <list> <file>
+  recipients export <list> [file]
+`
+
+// Maps an error to the exit code of its kind
+func exitCode(err error) int {
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, errUsage):
+		return exitUsage
+	case errors.Is(err, errCredentials):
+		return exitCredentials
+	case sendbit.IsListNotExist(err) || sendbit.IsRecipientNotExist(err):
+		return exitNotExist
+	case sendbit.IsRecipientExist(err):
+		return exitExist
+	case sendbit.IsInvalidEmail(err):
+		return exitInvalid
+	default:
+		return exitFailure
+	}
+}
+
+var errCredentials = errors.New("the client cannot be created")
+
+// Creates a flag set sharing the --output flag
+func (cmd *command) flags(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(cmd.stderr)
+	flags.StringVar(&cmd.output, "output", cmd.outputOrDefault(), "the output format: table, json or csv")
+	return flags
+}
+
+func (cmd *command) outputOrDefault() string {
+	if cmd.output == "" {
+		return "table"
+	}
+	return cmd.output
+}
+
+func (cmd *command) dispatch(args []string) error {
+	if len(args) < 2 {
+		return errUsage
+	}
+
+	switch cmd.output {
+	case "table", "json", "csv":
+	default:
+		return fmt.Errorf("%w: unknown output %q", errUsage, cmd.output)
+	}
+
+	client, err := cmd.connect()
+	if err != nil {
+		return fmt.Errorf("%w: %w", errCredentials, err)
+	}
+	cmd.client = client
+
+	group, name, args := args[0], args[1], args[2:]
+	switch group + " " + name {
+	case "lists ls":
+		return cmd.listLists(args)
+	case "lists create":
+		return cmd.createList(args)
+	case "lists rm":
+		return cmd.deleteList(args)
+	case "lists rename":
+		return cmd.renameList(args)
+	case "recipients ls":
+		return cmd.listRecipients(args)
+	case "recipients add":
+		return cmd.addRecipient(args)
+	case "recipients rm":
+		return cmd.deleteRecipients(args)
+	case "recipients count":
+		return cmd.countRecipients(args)
+	case "recipients import":
+		return cmd.importRecipients(args)
+	case "recipients export":
+		return cmd.exportRecipients(args)
+	default:
+		return errUsage
+	}
+}
+
+// Parses the flags of a subcommand and checks its argument count
+func (cmd *command) parse(flags *flag.FlagSet, args []string, min, max int) ([]string, error) {
+	if err := flags.Parse(args); err != nil {
+		return nil, errUsage
+	}
+
+	rest := flags.Args()
+	if len(rest) < min || (max >= 0 && len(rest) > max) {
+		return nil, errUsage
+	}
+	return rest, nil
+}
+
+func (cmd *command) listLists(args []string) error {
+	if _, err := cmd.parse(cmd.flags("lists ls"), args, 0, 0); err != nil {
+		return err
+	}
+
+	lists, err := cmd.client.Lists()
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, len(lists))
+	for index, list := range lists {
+		// The v3 lists are identified by their UUID only
+		id := list.UUID
+		if id == "" {
+			id = fmt.Sprint(list.ID)
+		}
+		rows[index] = []string{id, list.Name}
+	}
+	return cmd.print(lists, []string{"id", "name"}, rows)
+}
+
+func (cmd *command) createList(args []string) error {
+	rest, err := cmd.parse(cmd.flags("lists create"), args, 1, 1)
+	if err != nil {
+		return err
+	}
+	return cmd.client.CreateList(rest[0])
+}
+
+func (cmd *command) deleteList(args []string) error {
+	rest, err := cmd.parse(cmd.flags("lists rm"), args, 1, 1)
+	if err != nil {
+		return err
+	}
+	return cmd.client.DeleteList(rest[0])
+}
+
+func (cmd *command) renameList(args []string) error {
+	rest, err := cmd.parse(cmd.flags("lists rename"), args, 2, 2)
+	if err != nil {
+		return err
+	}
+	return cmd.client.RenameList(rest[0], rest[1])
+}
+
+func (cmd *command) listRecipients(args []string) error {
+	rest, err := cmd.parse(cmd.flags("recipients ls"), args, 1, 1)
+	if err != nil {
+		return err
+	}
+
+	if cmd.output == "csv" {
+		return cmd.client.ExportCSV(rest[0], cmd.stdout)
+	}
+
+	recipients, err := cmd.client.Recipients(rest[0])
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, len(recipients))
+	for index, recipient := range recipients {
+		rows[index] = []string{recipient.Email, recipient.Name}
+	}
+	return cmd.print(recipients, []string{"email", "name"}, rows)
+}
+
+func (cmd *command) addRecipient(args []string) error {
+	flags := cmd.flags("recipients add")
+	name := flags.String("name", "", "the recipient name")
+	fields := pairs{}
+	flags.Var(fields, "field", "a custom field as key=value")
+
+	rest, err := cmd.parse(flags, args, 2, 2)
+	if err != nil {
+		return err
+	}
+
+	recipient := &sendbit.Recipient{Name: *name, Email: rest[1]}
+	if len(fields) > 0 {
+		recipient.Fields = fields
+	}
+	return cmd.client.AddRecipient(rest[0], recipient)
+}
+
+func (cmd *command) deleteRecipients(args []string) error {
+	rest, err := cmd.parse(cmd.flags("recipients rm"), args, 2, -1)
+	if err != nil {
+		return err
+	}
+
+	if len(rest) == 2 {
+		return cmd.client.DeleteRecipient(rest[0], rest[1])
+	}
+
+	removed, err := cmd.client.DeleteRecipients(rest[0], rest[1:])
+	if err != nil {
+		return err
+	}
+	return cmd.print(map[string]int{"removed": removed}, []string{"removed"},
+		[][]string{{fmt.Sprint(removed)}})
+}
+
+func (cmd *command) countRecipients(args []string) error {
+	rest, err := cmd.parse(cmd.flags("recipients count"), args, 1, 1)
+	if err != nil {
+		return err
+	}
+
+	count, err := cmd.client.RecipientCount(rest[0])
+	if err != nil {
+		return err
+	}
+	return cmd.print(map[string]uint64{"count": count}, []string{"count"},
+		[][]string{{fmt.Sprint(count)}})
+}
+
+func (cmd *command) importRecipients(args []string) error {
+	flags := cmd.flags("recipients import")
+	mapping := pairs{}
+	flags.Var(mapping, "map", "a column mapping as column=field")
+
+	rest, err := cmd.parse(flags, args, 2, 2)
+	if err != nil {
+		return err
+	}
+
+	reader, err := open(rest[1])
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	var columns sendbit.CSVMapping
+	if len(mapping) > 0 {
+		columns = sendbit.CSVMapping(mapping)
+	}
+
+	report, err := cmd.client.ImportCSV(rest[0], reader, columns)
+	if err != nil {
+		return err
+	}
+
+	messages := make([]string, len(report.Errors))
+	for index := range report.Errors {
+		messages[index] = report.Errors[index].Error()
+	}
+
+	summary := map[string]interface{}{
+		"imported":     report.Imported,
+		"duplicates":   report.Duplicates,
+		"existing":     report.Existing,
+		"unsubscribed": report.Unsubscribed,
+		"errors":       messages,
+	}
+
+	for _, message := range messages {
+		fmt.Fprintf(cmd.stderr, "sendbit: %s\n", message)
+	}
+
+	if err := cmd.print(summary, []string{"imported", "duplicates", "existing", "unsubscribed", "errors"},
+		[][]string{{
+			fmt.Sprint(report.Imported),
+			fmt.Sprint(report.Duplicates),
+			fmt.Sprint(report.Existing),
+			fmt.Sprint(len(report.Unsubscribed)),
+			fmt.Sprint(len(report.Errors)),
+		}}); err != nil {
+		return err
+	}
+
+	if len(report.Errors) > 0 {
+		return fmt.Errorf("%d row(s) were not imported", len(report.Errors))
+	}
+	return nil
+}
+
+func (cmd *command) exportRecipients(args []string) error {
+	rest, err := cmd.parse(cmd.flags("recipients export"), args, 1, 2)
+	if err != nil {
+		return err
+	}
+
+	if len(rest) == 1 || rest[1] == "-" {
+		return cmd.client.ExportCSV(rest[0], cmd.stdout)
+	}
+
+	file, err := os.Create(rest[1])
+	if err != nil {
+		return err
+	}
+
+	if err := cmd.client.ExportCSV(rest[0], file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Opens a file or the standard input for "-"
+func open(path string) (io.ReadCloser, error) {
+	if path == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(path)
+}
+
+// A repeatable key=value flag
+type pairs map[string]string
+
+func (values pairs) String() string {
+	entries := make([]string, 0, len(values))
+	for key, value := range values {
+		entries = append(entries, key+"="+value)
+	}
+	return strings.Join(entries, ",")
+}
+
+func (values pairs) Set(entry string) error {
+	key, value, ok := strings.Cut(entry, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("%q is not a key=value pair", entry)
+	}
+	values[key] = value
+	return nil
+}
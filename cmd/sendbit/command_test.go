@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/svett/sendbit"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Command", func() {
+	var (
+		server   *httptest.Server
+		requests []string
+		stdout   *bytes.Buffer
+		stderr   *bytes.Buffer
+		connect  clientFactory
+	)
+
+	sendgrid := func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		path := strings.TrimPrefix(r.URL.Path, "/api")
+		requests = append(requests, path+"?"+r.PostForm.Get("list"))
+		encoder := json.NewEncoder(w)
+
+		switch path {
+		case "/newsletter/lists/get.json":
+			if r.PostForm.Get("list") == "missing" {
+				encoder.Encode(map[string]string{"error": "the title(s) 'missing' do not exist"})
+				return
+			}
+			encoder.Encode([]sendbit.List{{ID: 1, Name: "news"}, {ID: 2, Name: "offers"}})
+		case "/newsletter/lists/email/get.json":
+			encoder.Encode([]sendbit.Recipient{
+				{Name: "John Smith", Email: "j.smith@example.com", Fields: map[string]string{"plan": "pro"}},
+			})
+		case "/newsletter/lists/email/count.json":
+			encoder.Encode(map[string]int{"count": 42})
+		case "/newsletter/lists/email/delete.json":
+			removed := 0
+			for _, email := range r.PostForm["email[]"] {
+				if !strings.HasPrefix(email, "missing") {
+					removed++
+				}
+			}
+			encoder.Encode(map[string]int{"removed": removed})
+		case "/newsletter/lists/email/add.json":
+			encoder.Encode(map[string]int{"inserted": len(r.PostForm["data"]) + len(r.PostForm["data[]"])})
+		default:
+			encoder.Encode(map[string]string{"message": "success"})
+		}
+	}
+
+	execute := func(args ...string) int {
+		return run(args, stdout, stderr, connect)
+	}
+
+	BeforeEach(func() {
+		requests = nil
+		stdout = &bytes.Buffer{}
+		stderr = &bytes.Buffer{}
+		server = httptest.NewServer(http.HandlerFunc(sendgrid))
+		connect = func() (*sendbit.Client, error) {
+			client, err := sendbit.NewClient("user", "pass")
+			if client != nil {
+				client.Host = server.URL
+			}
+			return client, err
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("lists the lists as a table", func() {
+		Expect(execute("lists", "ls")).To(Equal(exitOK))
+		Expect(stdout.String()).To(Equal("ID  NAME\n1   news\n2   offers\n"))
+	})
+
+	It("lists the lists as JSON", func() {
+		Expect(execute("--output", "json", "lists", "ls")).To(Equal(exitOK))
+
+		var lists []sendbit.List
+		Expect(json.Unmarshal(stdout.Bytes(), &lists)).To(Succeed())
+		Expect(lists).To(HaveLen(2))
+	})
+
+	Context("when the v3 API is used", func() {
+		BeforeEach(func() {
+			server.Close()
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests = append(requests, r.Method+" "+r.URL.Path)
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"result":    []map[string]string{{"id": "ca7a3796", "name": "news"}},
+					"_metadata": map[string]string{},
+				})
+			}))
+			connect = func() (*sendbit.Client, error) {
+				client, err := sendbit.NewClient("apikey", "SG.key", sendbit.WithAPI(sendbit.MarketingAPI))
+				if client != nil {
+					client.Host = server.URL
+				}
+				return client, err
+			}
+		})
+
+		It("lists the lists by their UUID", func() {
+			Expect(execute("lists", "ls")).To(Equal(exitOK))
+			Expect(stdout.String()).To(Equal("ID        NAME\nca7a3796  news\n"))
+			Expect(requests).To(Equal([]string{"GET /v3/marketing/lists"}))
+		})
+	})
+
+	It("lists the recipients as CSV", func() {
+		Expect(execute("recipients", "ls", "--output", "csv", "news")).To(Equal(exitOK))
+		Expect(stdout.String()).To(Equal("email,name,plan\nj.smith@example.com,John Smith,pro\n"))
+	})
+
+	It("counts the recipients", func() {
+		Expect(execute("recipients", "count", "news")).To(Equal(exitOK))
+		Expect(stdout.String()).To(Equal("COUNT\n42\n"))
+	})
+
+	It("creates, renames and removes a list", func() {
+		Expect(execute("lists", "create", "news")).To(Equal(exitOK))
+		Expect(execute("lists", "rename", "news", "newsletter")).To(Equal(exitOK))
+		Expect(execute("lists", "rm", "newsletter")).To(Equal(exitOK))
+		Expect(requests).To(Equal([]string{
+			"/newsletter/lists/add.json?news",
+			"/newsletter/lists/edit.json?news",
+			"/newsletter/lists/delete.json?newsletter",
+		}))
+	})
+
+	It("adds a recipient with custom fields", func() {
+		Expect(execute("recipients", "add", "--name", "John", "--field", "plan=pro", "news", "j@example.com")).To(Equal(exitOK))
+		Expect(requests).To(Equal([]string{"/newsletter/lists/email/add.json?news"}))
+	})
+
+	It("removes a recipient", func() {
+		Expect(execute("recipients", "rm", "news", "j.smith@example.com")).To(Equal(exitOK))
+		Expect(requests).To(Equal([]string{"/newsletter/lists/email/delete.json?news"}))
+	})
+
+	It("imports a CSV file", func() {
+		dir, err := os.MkdirTemp("", "sendbit")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(dir)
+
+		path := filepath.Join(dir, "recipients.csv")
+		Expect(os.WriteFile(path, []byte("Mail\nj@example.com\nnot-an-email\n"), 0600)).To(Succeed())
+
+		Expect(execute("recipients", "import", "--map", "Mail=email", "news", path)).To(Equal(exitFailure))
+		Expect(stdout.String()).To(ContainSubstring("1         0           0         0             1"))
+		Expect(stderr.String()).To(ContainSubstring("line 3:"))
+	})
+
+	Describe("exit codes", func() {
+		It("reports a usage error", func() {
+			Expect(execute("lists")).To(Equal(exitUsage))
+			Expect(execute("lists", "create")).To(Equal(exitUsage))
+			Expect(execute("--output", "xml", "lists", "ls")).To(Equal(exitUsage))
+			Expect(stderr.String()).To(ContainSubstring("usage: sendbit"))
+		})
+
+		It("reports missing credentials", func() {
+			connect = func() (*sendbit.Client, error) {
+				return nil, errors.New("sendbit: Username argument cannot be empty.")
+			}
+			Expect(execute("lists", "ls")).To(Equal(exitCredentials))
+			Expect(stderr.String()).To(Equal("sendbit: the client cannot be created: " +
+				"sendbit: Username argument cannot be empty.\n"))
+		})
+
+		It("reports a missing recipient", func() {
+			Expect(execute("recipients", "rm", "news", "missing@example.com")).To(Equal(exitNotExist))
+			Expect(stderr.String()).To(Equal("sendbit: client.DeleteRecipient error: " +
+				"The recipient does not exist.\n"))
+		})
+
+		It("reports an invalid email", func() {
+			Expect(execute("recipients", "add", "news", "nobody")).To(Equal(exitInvalid))
+		})
+
+		It("maps the error kinds", func() {
+			Expect(exitCode(fmt.Errorf("sendbit: client.List error: the title(s) 'x' do not exist"))).To(Equal(exitNotExist))
+			Expect(exitCode(fmt.Errorf("wrapped: %w", &sendbit.ListNotExistError{Name: "x"}))).To(Equal(exitNotExist))
+			Expect(exitCode(errors.New("The recipient does not exist."))).To(Equal(exitNotExist))
+			Expect(exitCode(errors.New("The recipient already exist."))).To(Equal(exitExist))
+			Expect(exitCode(errors.New("EOF"))).To(Equal(exitFailure))
+		})
+	})
+})
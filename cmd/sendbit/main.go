@@ -0,0 +1,38 @@
+// Command sendbit administers the SendGrid recipient lists and recipients.
+//
+// The credentials are read from the SENDGRID_USER and SENDGRID_PASS
+// environment variables. The v3 Marketing Campaigns API is used instead
+// when the SENDGRID_API_KEY environment variable is set.
+//
+//	sendbit [--output table|json|csv] lists ls
+//	sendbit lists create <list>
+//	sendbit lists rm <list>
+//	sendbit lists rename <list> <new-name>
+//	sendbit recipients ls <list>
+//	sendbit recipients add [--name name] [--field key=value]... <list> <email>
+//	sendbit recipients rm <list> <email>...
+//	sendbit recipients count <list>
+//	sendbit recipients import [--map column=field]... <list> <file>
+//	sendbit recipients export <list> [file]
+//
+// The exit code is 0 on success, 2 on usage errors, 3 when a list or a
+// recipient does not exist, 4 when a recipient already exists, 5 when an
+// email is invalid, 6 when the credentials are missing and 1 otherwise.
+package main
+
+import (
+	"os"
+
+	"github.com/svett/sendbit"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr, connect))
+}
+
+func connect() (*sendbit.Client, error) {
+	if key := os.Getenv("SENDGRID_API_KEY"); key != "" {
+		return sendbit.NewClient("apikey", key, sendbit.WithAPI(sendbit.MarketingAPI))
+	}
+	return sendbit.NewClientFromEnv()
+}
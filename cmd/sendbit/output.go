@@ -0,0 +1,31 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"text/tabwriter"
+)
+
+// Prints a value in the selected output format. The JSON output encodes
+// the value itself while the table and the CSV outputs print the rows.
+func (cmd *command) print(value interface{}, header []string, rows [][]string) error {
+	switch cmd.output {
+	case "json":
+		encoder := json.NewEncoder(cmd.stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	case "csv":
+		writer := csv.NewWriter(cmd.stdout)
+		writer.Write(header)
+		writer.WriteAll(rows)
+		return writer.Error()
+	default:
+		writer := tabwriter.NewWriter(cmd.stdout, 0, 4, 2, ' ', 0)
+		writer.Write([]byte(strings.ToUpper(strings.Join(header, "\t")) + "\n"))
+		for _, row := range rows {
+			writer.Write([]byte(strings.Join(row, "\t") + "\n"))
+		}
+		return writer.Flush()
+	}
+}
@@ -0,0 +1,233 @@
+package sendbit
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Maps the CSV columns to the recipient fields. The keys are the column
+// headers and the values are "email", "name" or a custom field name.
+// The columns that are not mapped are ignored.
+type CSVMapping map[string]string
+
+// Represents an error of a particular CSV row
+type RowError struct {
+	// The line the row starts at
+	Line int
+	// The row error
+	Err error
+}
+
+func (err *RowError) Error() string {
+	return fmt.Sprintf("line %d: %s", err.Line, err.Err)
+}
+
+func (err *RowError) Unwrap() error {
+	return err.Err
+}
+
+// Summarizes a CSV import
+type ImportReport struct {
+	// The number of the recipients added to the list
+	Imported int
+	// The number of the rows whose email appears earlier in the file
+	Duplicates int
+	// The number of the recipients that already exist in the list
+	Existing int
+	// The skipped emails which are globally unsubscribed
+	Unsubscribed []string
+	// The rows that were not imported
+	Errors []RowError
+	// The jobs ingesting the recipients when the v3 API is used
+	Jobs []*Job
+}
+
+// Imports the recipients of a CSV file to a list. The first row is a header
+// whose columns are mapped to the recipient fields. A nil mapping maps every
+// column to the field with the same name. The rows are validated,
+// de-duplicated by email and added in batches of MaxBatchSize. The rows that
+// cannot be imported are reported with their line numbers. The v3 API
+// rejects the rows whose custom fields do not match their definitions.
+func (client *Client) ImportCSV(list string, reader io.Reader, mapping CSVMapping) (*ImportReport, error) {
+	ctx, span := client.startSpan("ImportCSV")
+	defer span.End()
+
+	errorf := func(err error) error {
+		return client.errorf("ImportCSV", err)
+	}
+
+	if list == "" {
+		return nil, errorf(errors.New("The list is empty."))
+	}
+
+	rows := csv.NewReader(reader)
+	header, err := rows.Read()
+	if err == io.EOF {
+		return nil, errorf(errors.New("The CSV header is missing."))
+	}
+	if err != nil {
+		return nil, errorf(err)
+	}
+
+	fields := make([]string, len(header))
+	hasEmail := false
+	for index, column := range header {
+		column = strings.TrimSpace(column)
+		field := column
+		if mapping != nil {
+			field = mapping[column]
+		}
+		fields[index] = field
+		hasEmail = hasEmail || field == "email"
+	}
+
+	if !hasEmail {
+		return nil, errorf(errors.New("The CSV email column is missing."))
+	}
+
+	report := &ImportReport{}
+	seen := map[string]bool{}
+	batch := make([]Recipient, 0, MaxBatchSize)
+	inner := client.WithContext(ctx)
+	backend := client.backend()
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		result, err := inner.AddRecipients(list, batch)
+		report.Imported += result.Inserted
+		report.Existing += result.Existing
+		report.Unsubscribed = append(report.Unsubscribed, result.Unsubscribed...)
+		report.Jobs = append(report.Jobs, result.Jobs...)
+		if err != nil {
+			return err
+		}
+
+		batch = batch[:0]
+		return nil
+	}
+
+	for {
+		row, err := rows.Read()
+		if err == io.EOF {
+			break
+		}
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			report.Errors = append(report.Errors, RowError{Line: parseErr.StartLine, Err: parseErr.Err})
+			continue
+		}
+		if err != nil {
+			return report, errorf(err)
+		}
+
+		line, _ := rows.FieldPos(0)
+		recipient := Recipient{}
+		for index, value := range row {
+			switch fields[index] {
+			case "":
+			case "email":
+				recipient.Email = strings.TrimSpace(value)
+			case "name":
+				recipient.Name = value
+			default:
+				if recipient.Fields == nil {
+					recipient.Fields = map[string]string{}
+				}
+				recipient.Fields[fields[index]] = value
+			}
+		}
+
+		normalized, err := client.normalizeRecipient(&recipient)
+		if err != nil {
+			report.Errors = append(report.Errors, RowError{Line: line, Err: err})
+			continue
+		}
+
+		if err := backend.checkRecipients(ctx, []Recipient{*normalized}); err != nil {
+			if !IsInvalidField(err) {
+				return report, errorf(err)
+			}
+			report.Errors = append(report.Errors, RowError{Line: line, Err: err})
+			continue
+		}
+
+		key := strings.ToLower(normalized.Email)
+		if seen[key] {
+			report.Duplicates++
+			continue
+		}
+		seen[key] = true
+
+		batch = append(batch, *normalized)
+		if len(batch) == MaxBatchSize {
+			if err := flush(); err != nil {
+				return report, err
+			}
+		}
+	}
+
+	if err := flush(); err != nil {
+		return report, err
+	}
+
+	return report, nil
+}
+
+// Exports the recipients of a list as CSV. The header starts with the
+// "email" and "name" columns followed by the sorted custom field names.
+func (client *Client) ExportCSV(list string, writer io.Writer) error {
+	ctx, span := client.startSpan("ExportCSV")
+	defer span.End()
+
+	errorf := func(err error) error {
+		return client.errorf("ExportCSV", err)
+	}
+
+	recipients, err := client.WithContext(ctx).Recipients(list)
+	if err != nil {
+		return err
+	}
+
+	custom := map[string]bool{}
+	for _, recipient := range recipients {
+		for name := range recipient.Fields {
+			custom[name] = true
+		}
+	}
+
+	header := []string{"email", "name"}
+	for name := range custom {
+		header = append(header, name)
+	}
+	sort.Strings(header[2:])
+
+	rows := csv.NewWriter(writer)
+	if err := rows.Write(header); err != nil {
+		return errorf(err)
+	}
+
+	for _, recipient := range recipients {
+		row := []string{recipient.Email, recipient.Name}
+		for _, name := range header[2:] {
+			row = append(row, recipient.Fields[name])
+		}
+		if err := rows.Write(row); err != nil {
+			return errorf(err)
+		}
+	}
+
+	rows.Flush()
+	if err := rows.Error(); err != nil {
+		return errorf(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package sendbit_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	. "github.com/svett/sendbit"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CSV", func() {
+	var (
+		fake   *FakeSendGrid
+		client *Client
+	)
+
+	BeforeEach(func() {
+		fake = NewFakeSendGrid()
+		fake.AddList("newsletter", Recipient{Name: "Mike T.", Email: "mike.t@example.com"})
+		client = fake.Client()
+	})
+
+	AfterEach(func() {
+		fake.Close()
+	})
+
+	Describe("ImportCSV", func() {
+		It("imports the mapped columns", func() {
+			csv := "E-mail,Full Name,Plan,Notes\n" +
+				"j.smith@EXAMPLE.com,John Smith,pro,ignored\n" +
+				"m.freeman@example.com,Morgan Freeman,free,ignored\n"
+
+			report, err := client.ImportCSV("newsletter", strings.NewReader(csv), CSVMapping{
+				"E-mail":    "email",
+				"Full Name": "name",
+				"Plan":      "plan",
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(report).To(Equal(&ImportReport{Imported: 2}))
+			Expect(fake.Recipients("newsletter")).To(Equal([]Recipient{
+				{Name: "Mike T.", Email: "mike.t@example.com"},
+				{Name: "John Smith", Email: "j.smith@example.com", Fields: map[string]string{"plan": "pro"}},
+				{Name: "Morgan Freeman", Email: "m.freeman@example.com", Fields: map[string]string{"plan": "free"}},
+			}))
+		})
+
+		It("reports the invalid, duplicated and existing rows", func() {
+			csv := "email,name\n" +
+				"j.smith@example.com,John Smith\n" +
+				"not an email,Nobody\n" +
+				"J.Smith@Example.com,John Smith\n" +
+				"mike.t@example.com,Mike T.\n" +
+				"\"broken,quote\n"
+
+			report, err := client.ImportCSV("newsletter", strings.NewReader(csv), nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(report.Imported).To(Equal(1))
+			Expect(report.Duplicates).To(Equal(1))
+			Expect(report.Existing).To(Equal(1))
+			Expect(report.Errors).To(HaveLen(2))
+			Expect(report.Errors[0].Line).To(Equal(3))
+			Expect(IsInvalidEmail(&report.Errors[0])).To(BeTrue())
+			Expect(report.Errors[1].Line).To(Equal(6))
+		})
+
+		It("adds the recipients in batches", func() {
+			csv := bytes.NewBufferString("email\n")
+			for index := 0; index < MaxBatchSize+1; index++ {
+				fmt.Fprintf(csv, "user%d@example.com\n", index)
+			}
+
+			report, err := client.ImportCSV("newsletter", csv, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(report.Imported).To(Equal(MaxBatchSize + 1))
+			Expect(fake.Requests).To(Equal([]string{
+				"/newsletter/lists/email/add.json",
+				"/newsletter/lists/email/add.json",
+			}))
+		})
+
+		Context("when the email column is missing", func() {
+			It("fails to import", func() {
+				_, err := client.ImportCSV("newsletter", strings.NewReader("name\nJohn\n"), nil)
+				Expect(err).To(MatchError("sendbit: client.ImportCSV error: The CSV email column is missing."))
+				Expect(fake.Requests).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("ExportCSV", func() {
+		It("writes a stable header", func() {
+			fake.AddList("newsletter",
+				Recipient{Name: "John Smith", Email: "j.smith@example.com", Fields: map[string]string{"plan": "pro", "city": "Sofia"}},
+				Recipient{Email: "m.freeman@example.com", Fields: map[string]string{"plan": "free"}},
+			)
+
+			output := &bytes.Buffer{}
+			Expect(client.ExportCSV("newsletter", output)).To(Succeed())
+			Expect(output.String()).To(Equal("email,name,city,plan\n" +
+				"mike.t@example.com,Mike T.,,\n" +
+				"j.smith@example.com,John Smith,Sofia,pro\n" +
+				"m.freeman@example.com,,,free\n"))
+		})
+	})
+})
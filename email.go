@@ -0,0 +1,172 @@
+package sendbit
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// Determines whether an error is 'InvalidEmailError' error.
+func IsInvalidEmail(err error) bool {
+	var invalid *InvalidEmailError
+	return errors.As(err, &invalid)
+}
+
+// Represents an email address rejected by the EmailValidator
+type InvalidEmailError struct {
+	// The rejected email address
+	Email string
+	// The reasons the address was rejected for
+	Problems []string
+}
+
+func (err *InvalidEmailError) Error() string {
+	return fmt.Sprintf("The email '%s' is invalid: %s.", err.Email,
+		strings.Join(err.Problems, ", "))
+}
+
+// Domains of well known disposable email providers
+var DisposableDomains = map[string]bool{
+	"10minutemail.com":  true,
+	"dispostable.com":   true,
+	"getnada.com":       true,
+	"guerrillamail.com": true,
+	"mailinator.com":    true,
+	"maildrop.cc":       true,
+	"sharklasers.com":   true,
+	"tempmail.com":      true,
+	"trashmail.com":     true,
+	"yopmail.com":       true,
+}
+
+// Local parts of role accounts which are not owned by a single person
+var RoleAccounts = map[string]bool{
+	"abuse":         true,
+	"admin":         true,
+	"administrator": true,
+	"billing":       true,
+	"contact":       true,
+	"help":          true,
+	"hostmaster":    true,
+	"info":          true,
+	"marketing":     true,
+	"no-reply":      true,
+	"noreply":       true,
+	"postmaster":    true,
+	"root":          true,
+	"sales":         true,
+	"security":      true,
+	"support":       true,
+	"webmaster":     true,
+}
+
+// Validates and normalizes the recipient email addresses.
+// The zero value accepts any RFC 5322 address with a qualified domain.
+type EmailValidator struct {
+	// RejectDisposable - Rejects addresses of disposable email providers
+	RejectDisposable bool
+	// DisposableDomains - The disposable domains. The package
+	// DisposableDomains are used when it is nil.
+	DisposableDomains map[string]bool
+	// RejectRole - Rejects role accounts such as admin@ or support@
+	RejectRole bool
+	// RoleAccounts - The role account local parts. The package
+	// RoleAccounts are used when it is nil.
+	RoleAccounts map[string]bool
+}
+
+// Validates an email address and returns its normalized form. The domain
+// is lowercased and internationalized domains are converted to punycode.
+// An *InvalidEmailError is returned for malformed or rejected addresses.
+func (validator *EmailValidator) Normalize(email string) (string, error) {
+	invalid := func(problems ...string) error {
+		return &InvalidEmailError{Email: email, Problems: problems}
+	}
+
+	address, err := mail.ParseAddress(email)
+	if err != nil {
+		return "", invalid(strings.TrimPrefix(err.Error(), "mail: "))
+	}
+	if address.Name != "" || strings.Contains(email, "<") {
+		return "", invalid("display names are not allowed")
+	}
+
+	at := strings.LastIndex(address.Address, "@")
+	local, domain := address.Address[:at], address.Address[at+1:]
+
+	// The parsed local part is unquoted, so a quoted one is kept as written
+	if text := strings.TrimSpace(email); strings.HasPrefix(text, `"`) {
+		local = text[:strings.LastIndex(text, "@")]
+	}
+
+	domain, err = idna.Lookup.ToASCII(domain)
+	if err != nil {
+		return "", invalid(fmt.Sprintf("invalid domain: %s", strings.TrimPrefix(err.Error(), "idna: ")))
+	}
+
+	var problems []string
+	if !strings.Contains(domain, ".") {
+		problems = append(problems, "the domain is not fully qualified")
+	}
+	if len(local) > 64 {
+		problems = append(problems, "the local part is longer than 64 characters")
+	}
+	if len(local)+len(domain)+1 > 254 {
+		problems = append(problems, "the address is longer than 254 characters")
+	}
+
+	disposable := validator.DisposableDomains
+	if disposable == nil {
+		disposable = DisposableDomains
+	}
+	if validator.RejectDisposable && disposable[domain] {
+		problems = append(problems, "the domain is disposable")
+	}
+
+	roles := validator.RoleAccounts
+	if roles == nil {
+		roles = RoleAccounts
+	}
+	if validator.RejectRole && roles[strings.ToLower(local)] {
+		problems = append(problems, "the address is a role account")
+	}
+
+	if len(problems) > 0 {
+		return "", invalid(problems...)
+	}
+
+	return local + "@" + domain, nil
+}
+
+// Normalizes an email address that is looked up or opted out rather than
+// added. Only the syntax is checked, so the role and disposable policies of
+// the client validator do not apply.
+func normalizeEmail(email string) (string, error) {
+	return (&EmailValidator{}).Normalize(email)
+}
+
+func (client *Client) validator() *EmailValidator {
+	if client.Validator == nil {
+		return &EmailValidator{}
+	}
+	return client.Validator
+}
+
+// Returns a copy of the recipient with a normalized email address
+func (client *Client) normalizeRecipient(recipient *Recipient) (*Recipient, error) {
+	if recipient == nil || recipient.Email == "" {
+		return nil, errors.New("The recipeint is nil or has invalid email.")
+	}
+
+	email, err := client.validator().Normalize(recipient.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := *recipient
+	normalized.Email = email
+	return &normalized, nil
+}
@@ -0,0 +1,128 @@
+package sendbit_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/svett/sendbit"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("EmailValidator", func() {
+	var validator *EmailValidator
+
+	BeforeEach(func() {
+		validator = &EmailValidator{}
+	})
+
+	It("lowercases the domain", func() {
+		Expect(validator.Normalize("John.Smith@Example.COM")).To(Equal("John.Smith@example.com"))
+	})
+
+	It("converts an internationalized domain to punycode", func() {
+		Expect(validator.Normalize("hans@Bücher.example")).To(Equal("hans@xn--bcher-kva.example"))
+	})
+
+	It("rejects a malformed address", func() {
+		_, err := validator.Normalize("john.smith@")
+		Expect(IsInvalidEmail(err)).To(BeTrue())
+	})
+
+	It("rejects an address with a display name", func() {
+		_, err := validator.Normalize("John Smith <j.smith@example.com>")
+		Expect(err).To(MatchError("The email 'John Smith <j.smith@example.com>' " +
+			"is invalid: display names are not allowed."))
+	})
+
+	It("accepts a quoted local part", func() {
+		Expect(validator.Normalize(`"john doe"@Example.com`)).To(Equal(`"john doe"@example.com`))
+	})
+
+	It("rejects an address in angle brackets", func() {
+		_, err := validator.Normalize("<j.smith@example.com>")
+		Expect(err).To(MatchError("The email '<j.smith@example.com>' " +
+			"is invalid: display names are not allowed."))
+	})
+
+	It("rejects an unqualified domain", func() {
+		_, err := validator.Normalize("root@localhost")
+		Expect(err).To(MatchError("The email 'root@localhost' is invalid: " +
+			"the domain is not fully qualified."))
+	})
+
+	It("accepts disposable and role addresses by default", func() {
+		Expect(validator.Normalize("admin@mailinator.com")).To(Equal("admin@mailinator.com"))
+	})
+
+	Context("when disposable and role addresses are rejected", func() {
+		BeforeEach(func() {
+			validator.RejectDisposable = true
+			validator.RejectRole = true
+		})
+
+		It("lists every problem", func() {
+			_, err := validator.Normalize("Admin@mailinator.com")
+
+			var invalid *InvalidEmailError
+			Expect(errors.As(err, &invalid)).To(BeTrue())
+			Expect(invalid.Email).To(Equal("Admin@mailinator.com"))
+			Expect(invalid.Problems).To(Equal([]string{
+				"the domain is disposable",
+				"the address is a role account",
+			}))
+		})
+
+		It("uses the configured domains", func() {
+			validator.DisposableDomains = map[string]bool{"example.org": true}
+			Expect(validator.Normalize("j.smith@mailinator.com")).To(Equal("j.smith@mailinator.com"))
+
+			_, err := validator.Normalize("j.smith@example.org")
+			Expect(IsInvalidEmail(err)).To(BeTrue())
+		})
+	})
+
+	Describe("Client.AddRecipient", func() {
+		var (
+			server *httptest.Server
+			client *Client
+			data   []string
+		)
+
+		BeforeEach(func() {
+			data = nil
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				data = append(data, r.FormValue("data"))
+				fmt.Fprint(w, `{"inserted": 1}`)
+			}))
+
+			var err error
+			client, err = NewClient("user", "pass")
+			Expect(err).ToNot(HaveOccurred())
+			client.Host = server.URL
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("sends the normalized address", func() {
+			recipient := &Recipient{Name: "John Smith", Email: "j.smith@EXAMPLE.com"}
+			Expect(client.AddRecipient("newsletter", recipient)).To(Succeed())
+			Expect(data).To(Equal([]string{`{"name":"John Smith","email":"j.smith@example.com"}`}))
+			Expect(recipient.Email).To(Equal("j.smith@EXAMPLE.com"))
+		})
+
+		It("does not send an invalid address", func() {
+			client.Validator = &EmailValidator{RejectRole: true}
+			err := client.AddRecipient("newsletter", &Recipient{Email: "info@example.com"})
+			Expect(IsInvalidEmail(err)).To(BeTrue())
+			Expect(err).To(MatchError("sendbit: client.AddRecipient error: The email " +
+				"'info@example.com' is invalid: the address is a role account."))
+			Expect(data).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,117 @@
+package sendbit
+
+import (
+	"errors"
+	"time"
+)
+
+// Configures an email erasure
+type ErasureOptions struct {
+	// Suppress - Adds the email to the global unsubscribes so that it
+	// cannot be subscribed again
+	Suppress bool
+}
+
+// Records an email erasure for auditing
+type ErasureReport struct {
+	// The erased email
+	Email string `json:"email"`
+	// The time the erasure started at
+	StartedAt time.Time `json:"started_at"`
+	// The time the erasure completed at
+	CompletedAt time.Time `json:"completed_at"`
+	// The lists the email was removed from
+	Lists []string `json:"lists"`
+	// Whether the contact of the email was deleted from the account. It
+	// is set for the v3 Marketing Campaigns API only.
+	ContactDeleted bool `json:"contact_deleted"`
+	// Whether the email was added to the global unsubscribes
+	Suppressed bool `json:"suppressed"`
+	// The lists the email could not be removed from with their errors
+	Failures map[string]string `json:"failures,omitempty"`
+}
+
+// Erases an email from every list of the account. The email is normalized
+// like in ListsForEmail and reported in its normalized form. The lists
+// containing the email are discovered with ListsForEmail and the email is
+// removed from each of them. With the v3 Marketing Campaigns API the
+// contact of the email is deleted from the account as well. The report
+// lists the performed deletions and the failed ones. A ListErrors error is
+// returned when some of the lists fail.
+func (client *Client) EraseEmail(email string, options *ErasureOptions) (*ErasureReport, error) {
+	ctx, span := client.startSpan("EraseEmail")
+	defer span.End()
+
+	errorf := func(err error) error {
+		return client.errorf("EraseEmail", err)
+	}
+
+	if email == "" {
+		return nil, errorf(errors.New("The email is empty."))
+	}
+
+	email, err := normalizeEmail(email)
+	if err != nil {
+		return nil, errorf(err)
+	}
+
+	if options == nil {
+		options = &ErasureOptions{}
+	}
+
+	report := &ErasureReport{
+		Email:     email,
+		StartedAt: time.Now().UTC(),
+		Lists:     []string{},
+	}
+
+	inner := client.WithContext(ctx)
+	memberships, err := inner.ListsForEmail(email)
+
+	errs := ListErrors{}
+	if !errors.As(err, &errs) && err != nil {
+		return nil, err
+	}
+
+	for _, membership := range memberships {
+		name := membership.List.Name
+		if err := inner.DeleteRecipient(name, membership.Recipient.Email); err != nil {
+			errs[name] = err
+			continue
+		}
+		report.Lists = append(report.Lists, name)
+	}
+
+	if len(errs) > 0 {
+		report.Failures = make(map[string]string, len(errs))
+		for name, err := range errs {
+			report.Failures[name] = err.Error()
+		}
+	}
+
+	if client.API == MarketingAPI {
+		backend := &marketingBackend{client: client}
+		deleted, err := backend.deleteContacts(ctx, []string{email})
+		if err != nil {
+			report.CompletedAt = time.Now().UTC()
+			return report, errorf(err)
+		}
+		report.ContactDeleted = deleted > 0
+	}
+
+	if options.Suppress {
+		if err := inner.AddUnsubscribe(email); err != nil {
+			report.CompletedAt = time.Now().UTC()
+			return report, err
+		}
+		report.Suppressed = true
+	}
+
+	report.CompletedAt = time.Now().UTC()
+	if len(errs) > 0 {
+		failSpan(ctx, errs)
+		return report, errorf(errs)
+	}
+
+	return report, nil
+}
@@ -0,0 +1,101 @@
+package sendbit_test
+
+import (
+	. "github.com/svett/sendbit"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("EraseEmail", func() {
+	var (
+		fake   *FakeSendGrid
+		client *Client
+	)
+	const email = "alice@example.com"
+
+	BeforeEach(func() {
+		fake = NewFakeSendGrid()
+		fake.AddList("news", Recipient{Name: "Alice", Email: email})
+		fake.AddList("offers", Recipient{Email: "bob@example.com"})
+		fake.AddList("billing", Recipient{Email: email})
+		client = fake.Client()
+	})
+
+	AfterEach(func() {
+		fake.Close()
+	})
+
+	It("removes the email from every list", func() {
+		report, err := client.EraseEmail(email, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Email).To(Equal(email))
+		Expect(report.Lists).To(Equal([]string{"news", "billing"}))
+		Expect(report.Suppressed).To(BeFalse())
+		Expect(report.Failures).To(BeEmpty())
+		Expect(report.CompletedAt).ToNot(BeTemporally("<", report.StartedAt))
+
+		Expect(fake.Recipients("news")).To(BeEmpty())
+		Expect(fake.Recipients("billing")).To(BeEmpty())
+		Expect(fake.Recipients("offers")).To(HaveLen(1))
+		Expect(fake.Unsubscribes).To(BeEmpty())
+	})
+
+	Context("when the email is not normalized", func() {
+		It("erases and reports the normalized email", func() {
+			report, err := client.EraseEmail("alice@EXAMPLE.com", &ErasureOptions{Suppress: true})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(report.Email).To(Equal(email))
+			Expect(report.Lists).To(Equal([]string{"news", "billing"}))
+			Expect(fake.Unsubscribes).To(Equal([]string{email}))
+		})
+	})
+
+	Context("when the email is suppressed", func() {
+		It("adds it to the global unsubscribes", func() {
+			report, err := client.EraseEmail(email, &ErasureOptions{Suppress: true})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(report.Suppressed).To(BeTrue())
+			Expect(fake.Unsubscribes).To(Equal([]string{email}))
+		})
+	})
+
+	Context("when the email cannot be removed from a list", func() {
+		It("reports the failure", func() {
+			fake.FailOnce("/newsletter/lists/email/delete.json", "internal error")
+			client.Concurrency = 1
+
+			report, err := client.EraseEmail(email, nil)
+			Expect(err).To(MatchError("sendbit: client.EraseEmail error: The call failed for 1 " +
+				"list(s): news: sendbit: client.DeleteRecipient error: internal error"))
+			Expect(report.Lists).To(Equal([]string{"billing"}))
+			Expect(report.Failures).To(Equal(map[string]string{
+				"news": "sendbit: client.DeleteRecipient error: internal error",
+			}))
+		})
+	})
+
+	Context("when the v3 API is used", func() {
+		var marketing *FakeMarketing
+
+		BeforeEach(func() {
+			marketing = NewFakeMarketing()
+			marketing.AddList("news", Recipient{Name: "Alice", Email: email})
+			marketing.AddList("offers", Recipient{Email: "bob@example.com"})
+			client = marketing.Client()
+		})
+
+		AfterEach(func() {
+			marketing.Close()
+		})
+
+		It("deletes the contact", func() {
+			report, err := client.EraseEmail(email, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(report.Lists).To(Equal([]string{"news"}))
+			Expect(report.ContactDeleted).To(BeTrue())
+			Expect(marketing.Contacts).ToNot(HaveKey(email))
+			Expect(marketing.Contacts).To(HaveKey("bob@example.com"))
+		})
+	})
+})
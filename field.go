@@ -0,0 +1,173 @@
+package sendbit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// The layouts accepted by the date custom fields
+var DateFieldLayouts = []string{"01/02/2006", "2006-01-02"}
+
+// Represents the kind of the values of a custom field
+type FieldType string
+
+const (
+	// A field of free text values
+	TextField FieldType = "Text"
+	// A field of numeric values
+	NumberField FieldType = "Number"
+	// A field of date values in one of the DateFieldLayouts
+	DateField FieldType = "Date"
+)
+
+// Represents the definition of a v3 custom field
+type FieldDefinition struct {
+	// The field identificator
+	ID string `json:"id"`
+	// The field name
+	Name string `json:"name"`
+	// The kind of the field values
+	Type FieldType `json:"field_type"`
+}
+
+// Determines whether a custom field value is rejected error
+func IsInvalidField(err error) bool {
+	var invalid *InvalidFieldError
+	return errors.As(err, &invalid)
+}
+
+// Represents a custom field value that does not match its definition
+type InvalidFieldError struct {
+	// The recipient email
+	Email string
+	// The field name
+	Field string
+	// The reason the value is rejected
+	Problem string
+}
+
+func (err *InvalidFieldError) Error() string {
+	return fmt.Sprintf("The field '%s' of '%s' is invalid: %s.", err.Field, err.Email, err.Problem)
+}
+
+// Checks a value against the field definition and converts it to the
+// type the API expects. A blank number or date is unset and returns nil.
+func (definition *FieldDefinition) value(text string) (interface{}, error) {
+	if text == "" && definition.Type != TextField {
+		return nil, nil
+	}
+
+	switch definition.Type {
+	case NumberField:
+		number, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("the value '%s' is not a number", text)
+		}
+		return number, nil
+	case DateField:
+		for _, layout := range DateFieldLayouts {
+			if date, err := time.Parse(layout, text); err == nil {
+				return date.Format(DateFieldLayouts[0]), nil
+			}
+		}
+		return nil, fmt.Errorf("the value '%s' is not a date", text)
+	default:
+		return text, nil
+	}
+}
+
+// List the v3 custom field definitions on your account
+func (client *Client) FieldDefinitions() ([]FieldDefinition, error) {
+	return client.FieldDefinitionPager().All(client.context())
+}
+
+// Page through the v3 custom field definitions on your account
+func (client *Client) FieldDefinitionPager() *Pager[FieldDefinition] {
+	return singlePager(client, "FieldDefinitions", func(ctx context.Context) ([]FieldDefinition, error) {
+		if err := client.requireMarketing(); err != nil {
+			return nil, err
+		}
+		return client.fieldDefinitions(ctx)
+	})
+}
+
+// Create a v3 custom field definition
+func (client *Client) CreateFieldDefinition(name string, kind FieldType) (*FieldDefinition, error) {
+	ctx, span := client.startSpan("CreateFieldDefinition")
+	defer span.End()
+
+	errorf := func(err error) error {
+		return client.errorf("CreateFieldDefinition", err)
+	}
+
+	if err := client.requireMarketing(); err != nil {
+		return nil, errorf(err)
+	}
+
+	if !fieldName.MatchString(name) {
+		return nil, errorf(fmt.Errorf("The field name '%s' is invalid.", name))
+	}
+
+	if kind != TextField && kind != NumberField && kind != DateField {
+		return nil, errorf(fmt.Errorf("The field type '%s' is not supported.", kind))
+	}
+
+	body := map[string]string{"name": name, "field_type": string(kind)}
+	response, err := client.request(ctx, http.MethodPost, "/marketing/field_definitions", body)
+	if err != nil {
+		return nil, errorf(err)
+	}
+
+	definition := &FieldDefinition{}
+	if err := json.NewDecoder(response).Decode(definition); err != nil {
+		return nil, errorf(err)
+	}
+
+	return definition, nil
+}
+
+// Remove a v3 custom field definition along with its values
+func (client *Client) DeleteFieldDefinition(id string) error {
+	ctx, span := client.startSpan("DeleteFieldDefinition")
+	defer span.End()
+
+	errorf := func(err error) error {
+		return client.errorf("DeleteFieldDefinition", err)
+	}
+
+	if err := client.requireMarketing(); err != nil {
+		return errorf(err)
+	}
+
+	if id == "" {
+		return errorf(errors.New("The field id is empty."))
+	}
+
+	if _, err := client.request(ctx, http.MethodDelete, "/marketing/field_definitions/"+url.PathEscape(id), nil); err != nil {
+		return errorf(err)
+	}
+
+	return nil
+}
+
+func (client *Client) fieldDefinitions(ctx context.Context) ([]FieldDefinition, error) {
+	response, err := client.request(ctx, http.MethodGet, "/marketing/field_definitions", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		CustomFields []FieldDefinition `json:"custom_fields"`
+	}
+	if err := json.NewDecoder(response).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	return result.CustomFields, nil
+}
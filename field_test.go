@@ -0,0 +1,134 @@
+package sendbit_test
+
+import (
+	"strings"
+
+	. "github.com/svett/sendbit"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FieldDefinition", func() {
+	var (
+		fake   *FakeMarketing
+		client *Client
+	)
+
+	BeforeEach(func() {
+		fake = NewFakeMarketing()
+		fake.AddList("newsletter")
+		fake.AddField("plan", "Text")
+		fake.AddField("score", "Number")
+		fake.AddField("renewal", "Date")
+		client = fake.Client()
+	})
+
+	AfterEach(func() {
+		fake.Close()
+	})
+
+	It("manages the custom field definitions", func() {
+		definition, err := client.CreateFieldDefinition("city_code", NumberField)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(definition.Name).To(Equal("city_code"))
+		Expect(definition.Type).To(Equal(NumberField))
+
+		definitions, err := client.FieldDefinitions()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(definitions).To(HaveLen(4))
+		Expect(definitions[3]).To(Equal(*definition))
+
+		Expect(client.DeleteFieldDefinition(definition.ID)).To(Succeed())
+		Expect(fake.Fields).To(HaveLen(3))
+	})
+
+	Context("when the field type is not supported", func() {
+		It("fails to create the definition", func() {
+			_, err := client.CreateFieldDefinition("plan", FieldType("Boolean"))
+			Expect(err).To(MatchError("sendbit: client.CreateFieldDefinition error: " +
+				"The field type 'Boolean' is not supported."))
+		})
+	})
+
+	Describe("adding recipients", func() {
+		It("sends the typed values by field identificator", func() {
+			Expect(client.AddRecipient("newsletter", &Recipient{
+				Email:  "j.smith@example.com",
+				Fields: map[string]string{"plan": "pro", "score": "4.5", "renewal": "2024-03-01"},
+			})).To(Succeed())
+
+			Expect(fake.Contacts["j.smith@example.com"].CustomFields).To(Equal(map[string]interface{}{
+				"plan":    "pro",
+				"score":   4.5,
+				"renewal": "03/01/2024",
+			}))
+		})
+
+		It("fetches the definitions once per call", func() {
+			_, err := client.AddRecipients("newsletter", []Recipient{
+				{Email: "a@example.com", Fields: map[string]string{"plan": "pro"}},
+				{Email: "b@example.com", Fields: map[string]string{"plan": "free"}},
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			fetched := 0
+			for _, request := range fake.Requests {
+				if request == "GET /v3/marketing/field_definitions" {
+					fetched++
+				}
+			}
+			Expect(fetched).To(Equal(1))
+		})
+
+		Context("when a field is not defined", func() {
+			It("does not send the recipients", func() {
+				_, err := client.AddRecipients("newsletter", []Recipient{
+					{Email: "a@example.com"},
+					{Email: "b@example.com", Fields: map[string]string{"city": "Sofia"}},
+				})
+				Expect(IsInvalidField(err)).To(BeTrue())
+				Expect(err).To(MatchError("sendbit: client.AddRecipients error: " +
+					"The field 'city' of 'b@example.com' is invalid: the field is not defined."))
+				Expect(fake.Contacts).To(BeEmpty())
+			})
+		})
+
+		Context("when a value does not match its type", func() {
+			It("does not send the recipient", func() {
+				err := client.AddRecipient("newsletter", &Recipient{
+					Email:  "a@example.com",
+					Fields: map[string]string{"score": "high"},
+				})
+				Expect(err).To(MatchError("sendbit: client.AddRecipient error: " +
+					"The field 'score' of 'a@example.com' is invalid: the value 'high' is not a number."))
+			})
+		})
+
+		It("reports the invalid CSV rows", func() {
+			csv := "email,renewal\n" +
+				"a@example.com,03/01/2024\n" +
+				"b@example.com,tomorrow\n"
+
+			report, err := client.ImportCSV("newsletter", strings.NewReader(csv), nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(report.Imported).To(Equal(1))
+			Expect(report.Errors).To(HaveLen(1))
+			Expect(report.Errors[0].Line).To(Equal(3))
+			Expect(IsInvalidField(&report.Errors[0])).To(BeTrue())
+		})
+
+		It("skips the blank number and date cells", func() {
+			csv := "email,renewal,score\n" +
+				"a@example.com,,\n" +
+				"b@example.com,03/01/2024,\n"
+
+			report, err := client.ImportCSV("newsletter", strings.NewReader(csv), nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(report.Imported).To(Equal(2))
+			Expect(report.Errors).To(BeEmpty())
+			Expect(fake.Contacts["a@example.com"].CustomFields).To(BeEmpty())
+			Expect(fake.Contacts["b@example.com"].CustomFields).To(HaveLen(1))
+		})
+	})
+})
@@ -0,0 +1,233 @@
+package sendbit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Represents a v3 unsubscribe group, a topic the recipients can opt out
+// of without unsubscribing from every email
+type UnsubscribeGroup struct {
+	// The group identificator
+	ID int64 `json:"id"`
+	// The group name shown on the preferences page
+	Name string `json:"name"`
+	// The group description shown on the preferences page
+	Description string `json:"description"`
+	// Whether the group is the default one
+	IsDefault bool `json:"is_default"`
+	// The number of the emails suppressed by the group
+	Unsubscribes int `json:"unsubscribes"`
+}
+
+func (group *UnsubscribeGroup) body() map[string]interface{} {
+	return map[string]interface{}{
+		"name":        group.Name,
+		"description": group.Description,
+		"is_default":  group.IsDefault,
+	}
+}
+
+// List the v3 unsubscribe groups on your account
+func (client *Client) UnsubscribeGroups() ([]UnsubscribeGroup, error) {
+	return client.UnsubscribeGroupPager().All(client.context())
+}
+
+// Page through the v3 unsubscribe groups on your account
+func (client *Client) UnsubscribeGroupPager() *Pager[UnsubscribeGroup] {
+	return singlePager(client, "UnsubscribeGroups", func(ctx context.Context) ([]UnsubscribeGroup, error) {
+		if err := client.requireMarketing(); err != nil {
+			return nil, err
+		}
+
+		var groups []UnsubscribeGroup
+		if err := client.call(ctx, http.MethodGet, "/asm/groups", nil, &groups); err != nil {
+			return nil, err
+		}
+		return groups, nil
+	})
+}
+
+// Get a v3 unsubscribe group
+func (client *Client) UnsubscribeGroup(id int64) (*UnsubscribeGroup, error) {
+	ctx, span := client.startSpan("UnsubscribeGroup")
+	defer span.End()
+
+	errorf := func(err error) error {
+		return client.errorf("UnsubscribeGroup", err)
+	}
+
+	if err := client.requireMarketing(); err != nil {
+		return nil, errorf(err)
+	}
+
+	group := &UnsubscribeGroup{}
+	if err := client.call(ctx, http.MethodGet, groupPath(id), nil, group); err != nil {
+		return nil, errorf(err)
+	}
+
+	return group, nil
+}
+
+// Create a v3 unsubscribe group
+func (client *Client) CreateUnsubscribeGroup(group *UnsubscribeGroup) (*UnsubscribeGroup, error) {
+	ctx, span := client.startSpan("CreateUnsubscribeGroup")
+	defer span.End()
+
+	errorf := func(err error) error {
+		return client.errorf("CreateUnsubscribeGroup", err)
+	}
+
+	if err := client.requireMarketing(); err != nil {
+		return nil, errorf(err)
+	}
+
+	if group == nil || group.Name == "" {
+		return nil, errorf(errors.New("The group name is empty."))
+	}
+
+	created := &UnsubscribeGroup{}
+	if err := client.call(ctx, http.MethodPost, "/asm/groups", group.body(), created); err != nil {
+		return nil, errorf(err)
+	}
+
+	return created, nil
+}
+
+// Change the name, the description and the default flag of a v3
+// unsubscribe group
+func (client *Client) UpdateUnsubscribeGroup(group *UnsubscribeGroup) (*UnsubscribeGroup, error) {
+	ctx, span := client.startSpan("UpdateUnsubscribeGroup")
+	defer span.End()
+
+	errorf := func(err error) error {
+		return client.errorf("UpdateUnsubscribeGroup", err)
+	}
+
+	if err := client.requireMarketing(); err != nil {
+		return nil, errorf(err)
+	}
+
+	if group == nil || group.Name == "" {
+		return nil, errorf(errors.New("The group name is empty."))
+	}
+
+	updated := &UnsubscribeGroup{}
+	if err := client.call(ctx, http.MethodPatch, groupPath(group.ID), group.body(), updated); err != nil {
+		return nil, errorf(err)
+	}
+
+	return updated, nil
+}
+
+// Remove a v3 unsubscribe group
+func (client *Client) DeleteUnsubscribeGroup(id int64) error {
+	ctx, span := client.startSpan("DeleteUnsubscribeGroup")
+	defer span.End()
+
+	errorf := func(err error) error {
+		return client.errorf("DeleteUnsubscribeGroup", err)
+	}
+
+	if err := client.requireMarketing(); err != nil {
+		return errorf(err)
+	}
+
+	if err := client.call(ctx, http.MethodDelete, groupPath(id), nil, nil); err != nil {
+		return errorf(err)
+	}
+
+	return nil
+}
+
+// List the emails that opted out of a v3 unsubscribe group
+func (client *Client) GroupSuppressions(id int64) ([]string, error) {
+	return client.GroupSuppressionPager(id).All(client.context())
+}
+
+// Page through the emails that opted out of a v3 unsubscribe group
+func (client *Client) GroupSuppressionPager(id int64) *Pager[string] {
+	return singlePager(client, "GroupSuppressions", func(ctx context.Context) ([]string, error) {
+		if err := client.requireMarketing(); err != nil {
+			return nil, err
+		}
+
+		var emails []string
+		if err := client.call(ctx, http.MethodGet, groupPath(id)+"/suppressions", nil, &emails); err != nil {
+			return nil, err
+		}
+		return emails, nil
+	})
+}
+
+// Opt emails out of a v3 unsubscribe group. The emails are normalized
+// without the role and disposable checks of Client.Validator.
+func (client *Client) AddGroupSuppressions(id int64, emails ...string) error {
+	ctx, span := client.startSpan("AddGroupSuppressions")
+	defer span.End()
+
+	errorf := func(err error) error {
+		return client.errorf("AddGroupSuppressions", err)
+	}
+
+	if err := client.requireMarketing(); err != nil {
+		return errorf(err)
+	}
+
+	if len(emails) == 0 {
+		return errorf(errors.New("The emails are empty."))
+	}
+
+	normalized := make([]string, len(emails))
+	for index, email := range emails {
+		var err error
+		if normalized[index], err = normalizeEmail(email); err != nil {
+			return errorf(err)
+		}
+	}
+
+	body := map[string][]string{"recipient_emails": normalized}
+	if err := client.call(ctx, http.MethodPost, groupPath(id)+"/suppressions", body, nil); err != nil {
+		return errorf(err)
+	}
+
+	return nil
+}
+
+// Opt an email back in to a v3 unsubscribe group. The email is normalized
+// like in AddGroupSuppressions.
+func (client *Client) DeleteGroupSuppression(id int64, email string) error {
+	ctx, span := client.startSpan("DeleteGroupSuppression")
+	defer span.End()
+
+	errorf := func(err error) error {
+		return client.errorf("DeleteGroupSuppression", err)
+	}
+
+	if err := client.requireMarketing(); err != nil {
+		return errorf(err)
+	}
+
+	if email == "" {
+		return errorf(errors.New("The email is empty."))
+	}
+
+	email, err := normalizeEmail(email)
+	if err != nil {
+		return errorf(err)
+	}
+
+	path := groupPath(id) + "/suppressions/" + url.PathEscape(email)
+	if err := client.call(ctx, http.MethodDelete, path, nil, nil); err != nil {
+		return errorf(err)
+	}
+
+	return nil
+}
+
+func groupPath(id int64) string {
+	return fmt.Sprintf("/asm/groups/%d", id)
+}
@@ -0,0 +1,115 @@
+package sendbit_test
+
+import (
+	. "github.com/svett/sendbit"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("UnsubscribeGroup", func() {
+	var (
+		fake   *FakeMarketing
+		client *Client
+	)
+
+	BeforeEach(func() {
+		fake = NewFakeMarketing()
+		client = fake.Client()
+	})
+
+	AfterEach(func() {
+		fake.Close()
+	})
+
+	It("creates, updates, gets and deletes a group", func() {
+		group, err := client.CreateUnsubscribeGroup(&UnsubscribeGroup{Name: "Weekly", Description: "Weekly news"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(group.ID).ToNot(BeZero())
+		Expect(group.Name).To(Equal("Weekly"))
+
+		group.Name = "Daily"
+		group.IsDefault = true
+		updated, err := client.UpdateUnsubscribeGroup(group)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(updated.Name).To(Equal("Daily"))
+		Expect(updated.IsDefault).To(BeTrue())
+
+		fetched, err := client.UnsubscribeGroup(group.ID)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fetched).To(Equal(updated))
+
+		groups, err := client.UnsubscribeGroups()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(groups).To(Equal([]UnsubscribeGroup{*updated}))
+
+		Expect(client.DeleteUnsubscribeGroup(group.ID)).To(Succeed())
+		Expect(fake.Groups).To(BeEmpty())
+	})
+
+	It("opts out role and disposable emails", func() {
+		client.Validator = &EmailValidator{RejectRole: true, RejectDisposable: true}
+		group, err := client.CreateUnsubscribeGroup(&UnsubscribeGroup{Name: "Weekly"})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(client.AddGroupSuppressions(group.ID, "admin@example.com", "a@mailinator.com")).To(Succeed())
+		Expect(fake.Groups[0].Emails).To(Equal([]string{"admin@example.com", "a@mailinator.com"}))
+	})
+
+	It("adds, lists and removes the group suppressions", func() {
+		group, err := client.CreateUnsubscribeGroup(&UnsubscribeGroup{Name: "Weekly"})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(client.AddGroupSuppressions(group.ID, "j.smith@EXAMPLE.com", "m.freeman@example.com")).To(Succeed())
+		emails, err := client.GroupSuppressions(group.ID)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(emails).To(Equal([]string{"j.smith@example.com", "m.freeman@example.com"}))
+
+		Expect(client.DeleteGroupSuppression(group.ID, "j.smith@EXAMPLE.com")).To(Succeed())
+		emails, err = client.GroupSuppressions(group.ID)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(emails).To(Equal([]string{"m.freeman@example.com"}))
+	})
+
+	Context("when the group does not exist", func() {
+		It("fails to get it", func() {
+			_, err := client.UnsubscribeGroup(42)
+			Expect(err).To(MatchError("sendbit: client.UnsubscribeGroup error: group not found"))
+		})
+	})
+
+	Context("when a suppressed email is invalid", func() {
+		It("fails to add the suppressions", func() {
+			group, err := client.CreateUnsubscribeGroup(&UnsubscribeGroup{Name: "Weekly"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(IsInvalidEmail(client.AddGroupSuppressions(group.ID, "nobody"))).To(BeTrue())
+		})
+	})
+
+	Context("when the client uses the legacy API", func() {
+		It("fails", func() {
+			client.API = LegacyAPI
+			_, err := client.UnsubscribeGroups()
+			Expect(err).To(MatchError("sendbit: client.UnsubscribeGroups error: " +
+				"The call requires the v3 Marketing Campaigns API."))
+		})
+	})
+
+	It("sends a message in a group", func() {
+		message := &Message{
+			From:               Address{Email: "news@example.com"},
+			To:                 []Address{{Email: "j.smith@example.com"}},
+			Subject:            "Hello",
+			Text:               "Hi John",
+			UnsubscribeGroupID: 7,
+			GroupsToDisplay:    []int64{7, 8},
+		}
+		Expect(client.Send(message)).To(Succeed())
+		Expect(fake.Mail[0]["asm"]).To(MatchJSON(`{"group_id": 7, "groups_to_display": [7, 8]}`))
+
+		message.UnsubscribeGroupID = 0
+		Expect(client.Send(message)).To(MatchError("sendbit: client.Send error: " +
+			"The groups to display require an unsubscribe group."))
+		Expect(fake.Mail).To(HaveLen(1))
+	})
+})
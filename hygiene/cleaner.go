@@ -0,0 +1,249 @@
+// Package hygiene removes the recipients that hard-bounce or report spam
+// from every list of a SendGrid account.
+//
+//	cleaner := hygiene.NewCleaner(client)
+//	cleaner.Policy.SoftBounceThreshold = 3
+//	http.Handle("/sendgrid/events", webhook.NewHandler(cleaner.Handle))
+package hygiene
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/svett/sendbit"
+	"github.com/svett/sendbit/webhook"
+)
+
+// Decides which recipients are removed
+type Policy struct {
+	// KeepHardBounces - Keeps the recipients that hard-bounce
+	KeepHardBounces bool
+	// KeepSpamReports - Keeps the recipients that report spam
+	KeepSpamReports bool
+	// RemoveUnsubscribes - Removes the recipients that unsubscribe globally
+	RemoveUnsubscribes bool
+	// SoftBounceThreshold - The number of soft bounces, blocks and
+	// deferred messages after which a recipient is removed. A message
+	// deferred many times is counted once. Soft bounces never remove a
+	// recipient when it is zero.
+	SoftBounceThreshold int
+}
+
+// Records a removal decision
+type Entry struct {
+	// The time of the decision
+	Time time.Time `json:"time"`
+	// The removed email
+	Email string `json:"email"`
+	// The reason for the removal such as "hard bounce"
+	Reason string `json:"reason"`
+	// The lists the email was removed from. In a dry run these are the
+	// lists it would have been removed from.
+	Lists []string `json:"lists"`
+	// Whether the removal was only planned
+	DryRun bool `json:"dry_run"`
+	// The error the removal failed with
+	Error string `json:"error,omitempty"`
+}
+
+// Removes the recipients from every list according to a policy. It
+// consumes the Event Webhook events with Handle or polls the bounces and
+// the spam reports with Poll. The soft bounces are counted in memory. A
+// bounce record returned by many polls and a message deferred many times
+// are counted once, and a hard bounce or a spam report record returned
+// by many polls is handled once.
+type Cleaner struct {
+	// Client - The client of the account
+	Client *sendbit.Client
+	// Policy - Decides which recipients are removed
+	Policy Policy
+	// DryRun - Records the removals without performing them
+	DryRun bool
+	// Audit - Called for every removal decision
+	Audit func(entry Entry)
+
+	mutex sync.Mutex
+	// The keys of the counted soft bounces by lower cased email
+	softBounces map[string]map[string]bool
+	// The keys of the polled hard bounce and spam report records that
+	// have been handled
+	polled map[string]bool
+}
+
+// Creates a new cleaner which removes the hard bounces and the spam reports
+func NewCleaner(client *sendbit.Client) *Cleaner {
+	return &Cleaner{Client: client}
+}
+
+// Processes a webhook event. It can be used as a webhook.Callback.
+func (cleaner *Cleaner) Handle(ctx context.Context, event *webhook.Event) error {
+	switch {
+	case event.Type == webhook.Bounce && event.BounceType != "blocked":
+		if !cleaner.Policy.KeepHardBounces {
+			return cleaner.remove(ctx, event.Email, "hard bounce")
+		}
+	case event.Type == webhook.Bounce:
+		return cleaner.softBounce(ctx, event.Email, eventKey("event", event.ID))
+	case event.Type == webhook.Deferred:
+		// Every retry of a deferred message posts an event
+		return cleaner.softBounce(ctx, event.Email, eventKey("message", event.MessageID))
+	case event.Type == webhook.SpamReport:
+		if !cleaner.Policy.KeepSpamReports {
+			return cleaner.remove(ctx, event.Email, "spam report")
+		}
+	case event.Type == webhook.Unsubscribe:
+		if cleaner.Policy.RemoveUnsubscribes {
+			return cleaner.remove(ctx, event.Email, "unsubscribe")
+		}
+	}
+	return nil
+}
+
+// Processes the bounces and the spam reports matching a filter
+func (cleaner *Cleaner) Poll(ctx context.Context, filter *sendbit.SuppressionFilter) error {
+	client := cleaner.Client.WithContext(ctx)
+
+	bounces, err := client.Bounces(filter)
+	if err != nil {
+		return err
+	}
+
+	for _, bounce := range bounces {
+		// The polls return the stored records again, so they are keyed
+		// by their creation time
+		created := bounce.Created.Format(sendbit.TimestampLayout)
+		if !bounce.Hard() {
+			err = cleaner.softBounce(ctx, bounce.Email, "bounce "+created)
+		} else if !cleaner.Policy.KeepHardBounces {
+			err = cleaner.removeOnce(ctx, bounce.Email, "hard bounce", created)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if cleaner.Policy.KeepSpamReports {
+		return nil
+	}
+
+	reports, err := client.SpamReports(filter)
+	if err != nil {
+		return err
+	}
+
+	for _, report := range reports {
+		created := report.Created.Format(sendbit.TimestampLayout)
+		if err := cleaner.removeOnce(ctx, report.Email, "spam report", created); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Removes the recipient of a polled record unless the record created at
+// that time has been handled already. A failed removal is retried by the
+// next poll.
+func (cleaner *Cleaner) removeOnce(ctx context.Context, email, reason, created string) error {
+	key := strings.ToLower(email) + " " + reason + " " + created
+
+	cleaner.mutex.Lock()
+	handled := cleaner.polled[key]
+	cleaner.mutex.Unlock()
+	if handled {
+		return nil
+	}
+
+	if err := cleaner.remove(ctx, email, reason); err != nil {
+		return err
+	}
+
+	cleaner.mutex.Lock()
+	if cleaner.polled == nil {
+		cleaner.polled = map[string]bool{}
+	}
+	cleaner.polled[key] = true
+	cleaner.mutex.Unlock()
+	return nil
+}
+
+// Counts a soft bounce identified by a key. The bounces without a key
+// are always counted.
+func (cleaner *Cleaner) softBounce(ctx context.Context, email, key string) error {
+	threshold := cleaner.Policy.SoftBounceThreshold
+	if threshold <= 0 {
+		return nil
+	}
+
+	cleaner.mutex.Lock()
+	if cleaner.softBounces == nil {
+		cleaner.softBounces = map[string]map[string]bool{}
+	}
+	address := strings.ToLower(email)
+	counted := cleaner.softBounces[address]
+	if counted == nil {
+		counted = map[string]bool{}
+		cleaner.softBounces[address] = counted
+	}
+	if key == "" {
+		key = fmt.Sprintf("#%d", len(counted))
+	}
+	counted[key] = true
+	count := len(counted)
+	if count >= threshold {
+		delete(cleaner.softBounces, address)
+	}
+	cleaner.mutex.Unlock()
+
+	if count < threshold {
+		return nil
+	}
+	return cleaner.remove(ctx, email, fmt.Sprintf("%d soft bounces", count))
+}
+
+// Builds the key of a soft bounce event. It is empty when the event has
+// no identifier.
+func eventKey(kind, id string) string {
+	if id == "" {
+		return ""
+	}
+	return kind + " " + id
+}
+
+func (cleaner *Cleaner) remove(ctx context.Context, email, reason string) error {
+	client := cleaner.Client.WithContext(ctx)
+	entry := Entry{
+		Time:   time.Now().UTC(),
+		Email:  email,
+		Reason: reason,
+		Lists:  []string{},
+		DryRun: cleaner.DryRun,
+	}
+
+	memberships, err := client.ListsForEmail(email)
+	for _, membership := range memberships {
+		if !cleaner.DryRun {
+			if err := client.DeleteRecipient(membership.List.Name, membership.Recipient.Email); err != nil {
+				entry.Error = err.Error()
+				cleaner.record(entry)
+				return err
+			}
+		}
+		entry.Lists = append(entry.Lists, membership.List.Name)
+	}
+
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	cleaner.record(entry)
+	return err
+}
+
+func (cleaner *Cleaner) record(entry Entry) {
+	if cleaner.Audit != nil {
+		cleaner.Audit(entry)
+	}
+}
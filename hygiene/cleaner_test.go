@@ -0,0 +1,180 @@
+package hygiene_test
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/svett/sendbit/webhook"
+
+	. "github.com/svett/sendbit/hygiene"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cleaner", func() {
+	var (
+		fake    *FakeSendGrid
+		cleaner *Cleaner
+		entries []Entry
+	)
+
+	handle := func(eventType webhook.EventType, bounceType, email string) error {
+		return cleaner.Handle(context.Background(), &webhook.Event{
+			Type:       eventType,
+			BounceType: bounceType,
+			Email:      email,
+		})
+	}
+
+	BeforeEach(func() {
+		fake = NewFakeSendGrid()
+		fake.AddList("news", "a@example.com", "b@example.com")
+		fake.AddList("offers", "a@example.com")
+
+		entries = nil
+		cleaner = NewCleaner(fake.Client())
+		cleaner.Audit = func(entry Entry) {
+			entries = append(entries, entry)
+		}
+	})
+
+	AfterEach(func() {
+		fake.Close()
+	})
+
+	It("removes a hard bounce from every list", func() {
+		Expect(handle(webhook.Bounce, "bounce", "a@example.com")).To(Succeed())
+		Expect(fake.Emails("news")).To(Equal([]string{"b@example.com"}))
+		Expect(fake.Emails("offers")).To(BeEmpty())
+
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].Email).To(Equal("a@example.com"))
+		Expect(entries[0].Reason).To(Equal("hard bounce"))
+		Expect(entries[0].Lists).To(Equal([]string{"news", "offers"}))
+		Expect(entries[0].DryRun).To(BeFalse())
+	})
+
+	It("removes a spam report", func() {
+		Expect(handle(webhook.SpamReport, "", "b@example.com")).To(Succeed())
+		Expect(fake.Emails("news")).To(Equal([]string{"a@example.com"}))
+	})
+
+	It("ignores the engagement events", func() {
+		Expect(handle(webhook.Open, "", "a@example.com")).To(Succeed())
+		Expect(handle(webhook.Unsubscribe, "", "a@example.com")).To(Succeed())
+		Expect(entries).To(BeEmpty())
+	})
+
+	Context("when the soft bounce threshold is set", func() {
+		BeforeEach(func() {
+			cleaner.Policy.SoftBounceThreshold = 2
+		})
+
+		It("counts a deferred message once", func() {
+			for attempt := 0; attempt < 3; attempt++ {
+				Expect(cleaner.Handle(context.Background(), &webhook.Event{
+					Type:      webhook.Deferred,
+					Email:     "a@example.com",
+					ID:        fmt.Sprintf("event-%d", attempt),
+					MessageID: "message-1",
+				})).To(Succeed())
+			}
+			Expect(entries).To(BeEmpty())
+		})
+
+		It("does not count the polled bounces again", func() {
+			fake.Suppressions["bounces"] = []map[string]string{
+				{"email": "a@example.com", "status": "4.2.2", "created": "2015-06-01 10:00:00"},
+			}
+			cleaner.Policy.KeepSpamReports = true
+
+			for poll := 0; poll < 3; poll++ {
+				Expect(cleaner.Poll(context.Background(), nil)).To(Succeed())
+			}
+			Expect(entries).To(BeEmpty())
+
+			fake.Suppressions["bounces"] = append(fake.Suppressions["bounces"],
+				map[string]string{"email": "a@example.com", "status": "4.2.2", "created": "2015-06-02 10:00:00"})
+			Expect(cleaner.Poll(context.Background(), nil)).To(Succeed())
+			Expect(entries).To(HaveLen(1))
+			Expect(entries[0].Reason).To(Equal("2 soft bounces"))
+		})
+
+		It("removes a recipient once the threshold is reached", func() {
+			Expect(handle(webhook.Bounce, "blocked", "a@example.com")).To(Succeed())
+			Expect(entries).To(BeEmpty())
+
+			Expect(handle(webhook.Deferred, "", "a@example.com")).To(Succeed())
+			Expect(entries).To(HaveLen(1))
+			Expect(entries[0].Reason).To(Equal("2 soft bounces"))
+			Expect(fake.Emails("offers")).To(BeEmpty())
+		})
+	})
+
+	Context("when soft bounces are not counted", func() {
+		It("keeps the recipient", func() {
+			for index := 0; index < 5; index++ {
+				Expect(handle(webhook.Bounce, "blocked", "a@example.com")).To(Succeed())
+			}
+			Expect(entries).To(BeEmpty())
+		})
+	})
+
+	Context("when the hard bounces are kept", func() {
+		It("does not remove them", func() {
+			cleaner.Policy.KeepHardBounces = true
+			Expect(handle(webhook.Bounce, "bounce", "a@example.com")).To(Succeed())
+			Expect(fake.Emails("offers")).To(Equal([]string{"a@example.com"}))
+		})
+	})
+
+	Context("when it is a dry run", func() {
+		It("records the removals without performing them", func() {
+			cleaner.DryRun = true
+			Expect(handle(webhook.Bounce, "bounce", "a@example.com")).To(Succeed())
+			Expect(fake.Emails("offers")).To(Equal([]string{"a@example.com"}))
+			Expect(entries).To(HaveLen(1))
+			Expect(entries[0].DryRun).To(BeTrue())
+			Expect(entries[0].Lists).To(Equal([]string{"news", "offers"}))
+		})
+	})
+
+	Describe("Poll", func() {
+		It("processes the bounces and the spam reports", func() {
+			fake.Suppressions["bounces"] = []map[string]string{
+				{"email": "a@example.com", "status": "5.1.1", "created": "2015-06-01 10:00:00"},
+				{"email": "b@example.com", "status": "4.2.2", "created": "2015-06-01 10:00:00"},
+			}
+			fake.Suppressions["spamreports"] = []map[string]string{
+				{"email": "b@example.com", "created": "2015-06-01 10:00:00"},
+			}
+
+			Expect(cleaner.Poll(context.Background(), nil)).To(Succeed())
+			Expect(fake.Emails("news")).To(BeEmpty())
+			Expect(entries).To(HaveLen(2))
+			Expect(entries[1].Reason).To(Equal("spam report"))
+		})
+
+		It("does not handle the polled records again", func() {
+			fake.Suppressions["bounces"] = []map[string]string{
+				{"email": "a@example.com", "status": "5.1.1", "created": "2015-06-01 10:00:00"},
+			}
+			fake.Suppressions["spamreports"] = []map[string]string{
+				{"email": "b@example.com", "created": "2015-06-01 10:00:00"},
+			}
+
+			for poll := 0; poll < 3; poll++ {
+				Expect(cleaner.Poll(context.Background(), nil)).To(Succeed())
+			}
+			Expect(entries).To(HaveLen(2))
+
+			fake.AddList("billing", "a@example.com")
+			fake.Suppressions["bounces"] = append(fake.Suppressions["bounces"],
+				map[string]string{"email": "a@example.com", "status": "5.1.1", "created": "2015-06-02 10:00:00"})
+			Expect(cleaner.Poll(context.Background(), nil)).To(Succeed())
+			Expect(entries).To(HaveLen(3))
+			Expect(entries[2].Lists).To(Equal([]string{"billing"}))
+		})
+	})
+})
@@ -0,0 +1,111 @@
+package hygiene_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/svett/sendbit"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestHygiene(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Hygiene Suite")
+}
+
+// An in-memory SendGrid API serving the lists and the suppressions
+type FakeSendGrid struct {
+	*httptest.Server
+
+	mutex sync.Mutex
+	// The emails of every list
+	Lists map[string][]string
+	// The list names in order
+	Names []string
+	// The suppression records by kind such as "bounces"
+	Suppressions map[string][]map[string]string
+}
+
+func NewFakeSendGrid() *FakeSendGrid {
+	fake := &FakeSendGrid{
+		Lists:        map[string][]string{},
+		Suppressions: map[string][]map[string]string{},
+	}
+	fake.Server = httptest.NewServer(http.HandlerFunc(fake.serve))
+	return fake
+}
+
+// Returns a client connected to the fake
+func (fake *FakeSendGrid) Client() *sendbit.Client {
+	client, err := sendbit.NewClient("user", "pass")
+	Expect(err).ToNot(HaveOccurred())
+	client.Host = fake.URL
+	return client
+}
+
+// Adds a list with its emails
+func (fake *FakeSendGrid) AddList(name string, emails ...string) {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	fake.Names = append(fake.Names, name)
+	fake.Lists[name] = emails
+}
+
+// Returns the emails of a list
+func (fake *FakeSendGrid) Emails(name string) []string {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	return append([]string{}, fake.Lists[name]...)
+}
+
+func (fake *FakeSendGrid) serve(w http.ResponseWriter, r *http.Request) {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+
+	r.ParseForm()
+	encoder := json.NewEncoder(w)
+	list := r.PostForm.Get("list")
+
+	switch strings.TrimPrefix(r.URL.Path, "/api") {
+	case "/newsletter/lists/get.json":
+		lists := []sendbit.List{}
+		for index, name := range fake.Names {
+			lists = append(lists, sendbit.List{ID: uint64(index + 1), Name: name})
+		}
+		encoder.Encode(lists)
+	case "/newsletter/lists/email/get.json":
+		recipients := []sendbit.Recipient{}
+		for _, email := range fake.Lists[list] {
+			if email == r.PostForm.Get("email") {
+				recipients = append(recipients, sendbit.Recipient{Email: email})
+			}
+		}
+		encoder.Encode(recipients)
+	case "/newsletter/lists/email/delete.json":
+		removed := 0
+		emails := []string{}
+		for _, email := range fake.Lists[list] {
+			if email == r.PostForm.Get("email[]") {
+				removed++
+			} else {
+				emails = append(emails, email)
+			}
+		}
+		fake.Lists[list] = emails
+		encoder.Encode(map[string]int{"removed": removed})
+	case "/bounces.get.json":
+		encoder.Encode(fake.Suppressions["bounces"])
+	case "/spamreports.get.json":
+		encoder.Encode(fake.Suppressions["spamreports"])
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		encoder.Encode(map[string]string{"error": "unknown endpoint " + r.URL.Path})
+	}
+}
@@ -0,0 +1,200 @@
+package inbound
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+)
+
+// Represents the SMTP envelope of an inbound email
+type Envelope struct {
+	// The envelope sender
+	From string `json:"from"`
+	// The envelope recipients
+	To []string `json:"to"`
+}
+
+// Represents an attachment of an inbound email
+type Attachment struct {
+	// The attachment file name
+	Filename string
+	// The attachment media type
+	ContentType string
+	// The content identifier of an inline attachment
+	ContentID string
+	// The attachment size in bytes
+	Size int64
+
+	open func() (io.ReadCloser, error)
+}
+
+// Opens the attachment content. Large attachments are streamed from a
+// temporary file that is removed once the handler callback returns.
+func (attachment *Attachment) Open() (io.ReadCloser, error) {
+	return attachment.open()
+}
+
+// Represents an email received by the SendGrid Inbound Parse webhook
+type InboundEmail struct {
+	// The email headers
+	Headers textproto.MIMEHeader
+	// The sender of the From header
+	From *mail.Address
+	// The recipients of the To header
+	To []*mail.Address
+	// The recipients of the Cc header
+	Cc []*mail.Address
+	// The decoded subject
+	Subject string
+	// The plain text body
+	Text string
+	// The HTML body
+	HTML string
+	// The SMTP envelope
+	Envelope Envelope
+	// The IP address of the sending server
+	SenderIP string
+	// The SPF verification result such as "pass"
+	SPF string
+	// The DKIM verification results such as "{@example.com : pass}"
+	DKIM string
+	// The spam score when spam checking is enabled
+	SpamScore string
+	// The spam report when spam checking is enabled
+	SpamReport string
+	// The character sets of the form fields such as "text" or "subject"
+	Charsets map[string]string
+	// The attachments
+	Attachments []Attachment
+	// Whether the email was posted as a raw MIME message
+	Raw bool
+}
+
+var wordDecoder = &mime.WordDecoder{}
+
+// Parses a raw MIME message as posted by the Inbound Parse webhook when
+// "POST the raw, full MIME message" is enabled. The text and HTML bodies
+// are taken from the first matching parts and every other part with a
+// file name or an attachment disposition becomes an attachment.
+func ParseRaw(reader io.Reader) (*InboundEmail, error) {
+	message, err := mail.ReadMessage(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	email := &InboundEmail{Raw: true}
+	email.setHeaders(textproto.MIMEHeader(message.Header))
+	if err := email.parsePart(textproto.MIMEHeader(message.Header), message.Body); err != nil {
+		return nil, err
+	}
+	return email, nil
+}
+
+func (email *InboundEmail) setHeaders(headers textproto.MIMEHeader) {
+	email.Headers = headers
+	header := mail.Header(headers)
+
+	if subject, err := wordDecoder.DecodeHeader(header.Get("Subject")); err == nil {
+		email.Subject = subject
+	} else {
+		email.Subject = header.Get("Subject")
+	}
+
+	if from, err := header.AddressList("From"); err == nil && len(from) > 0 {
+		email.From = from[0]
+	}
+	email.To, _ = header.AddressList("To")
+	email.Cc, _ = header.AddressList("Cc")
+}
+
+func (email *InboundEmail) parsePart(header textproto.MIMEHeader, body io.Reader) error {
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		parts := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := parts.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			if err := email.parsePart(part.Header, part); err != nil {
+				return err
+			}
+		}
+	}
+
+	switch strings.ToLower(header.Get("Content-Transfer-Encoding")) {
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, newlineStripper{body})
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	}
+
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	disposition, dispositionParams, _ := mime.ParseMediaType(header.Get("Content-Disposition"))
+	filename := dispositionParams["filename"]
+	if filename == "" {
+		filename = params["name"]
+	}
+
+	switch {
+	case disposition == "attachment" || filename != "":
+		email.Attachments = append(email.Attachments, Attachment{
+			Filename:    filename,
+			ContentType: mediaType,
+			ContentID:   strings.Trim(header.Get("Content-ID"), "<>"),
+			Size:        int64(len(content)),
+			open: func() (io.ReadCloser, error) {
+				return io.NopCloser(bytes.NewReader(content)), nil
+			},
+		})
+	case mediaType == "text/plain" && email.Text == "":
+		email.Text = string(content)
+	case mediaType == "text/html" && email.HTML == "":
+		email.HTML = string(content)
+	}
+
+	return nil
+}
+
+// Parses the raw header block posted in the "headers" field
+func parseHeaders(headers string) (textproto.MIMEHeader, error) {
+	reader := textproto.NewReader(bufio.NewReader(strings.NewReader(
+		strings.TrimRight(headers, "\r\n") + "\r\n\r\n")))
+	return reader.ReadMIMEHeader()
+}
+
+// Drops the line breaks of a base64 encoded body
+type newlineStripper struct {
+	reader io.Reader
+}
+
+func (stripper newlineStripper) Read(buffer []byte) (int, error) {
+	count, err := stripper.reader.Read(buffer)
+	kept := 0
+	for _, char := range buffer[:count] {
+		if char != '\r' && char != '\n' {
+			buffer[kept] = char
+			kept++
+		}
+	}
+	return kept, err
+}
@@ -0,0 +1,70 @@
+package inbound_test
+
+import (
+	"io"
+	"strings"
+
+	. "github.com/svett/sendbit/inbound"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const rawMessage = "From: John Smith <j.smith@example.com>\r\n" +
+	"To: support@example.org, Mike <mike.t@example.org>\r\n" +
+	"Subject: =?UTF-8?Q?Caf=C3=A9?=\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/mixed; boundary=outer\r\n" +
+	"\r\n" +
+	"--outer\r\n" +
+	"Content-Type: multipart/alternative; boundary=inner\r\n" +
+	"\r\n" +
+	"--inner\r\n" +
+	"Content-Type: text/plain; charset=UTF-8\r\n" +
+	"Content-Transfer-Encoding: quoted-printable\r\n" +
+	"\r\n" +
+	"Caf=C3=A9 au lait\r\n" +
+	"--inner\r\n" +
+	"Content-Type: text/html; charset=UTF-8\r\n" +
+	"\r\n" +
+	"<p>Hello</p>\r\n" +
+	"--inner--\r\n" +
+	"--outer\r\n" +
+	"Content-Type: text/plain; name=notes.txt\r\n" +
+	"Content-Disposition: attachment; filename=notes.txt\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"\r\n" +
+	"aGVsbG8g\r\n" +
+	"d29ybGQ=\r\n" +
+	"--outer--\r\n"
+
+var _ = Describe("ParseRaw", func() {
+	It("parses a raw MIME message", func() {
+		email, err := ParseRaw(strings.NewReader(rawMessage))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(email.Raw).To(BeTrue())
+		Expect(email.From.Address).To(Equal("j.smith@example.com"))
+		Expect(email.To).To(HaveLen(2))
+		Expect(email.To[1].Name).To(Equal("Mike"))
+		Expect(email.Subject).To(Equal("Café"))
+		Expect(email.Text).To(Equal("Café au lait"))
+		Expect(email.HTML).To(Equal("<p>Hello</p>"))
+		Expect(email.Headers.Get("Mime-Version")).To(Equal("1.0"))
+
+		Expect(email.Attachments).To(HaveLen(1))
+		attachment := email.Attachments[0]
+		Expect(attachment.Filename).To(Equal("notes.txt"))
+		Expect(attachment.ContentType).To(Equal("text/plain"))
+		Expect(attachment.Size).To(Equal(int64(11)))
+
+		reader, err := attachment.Open()
+		Expect(err).ToNot(HaveOccurred())
+		defer reader.Close()
+		Expect(io.ReadAll(reader)).To(Equal([]byte("hello world")))
+	})
+
+	It("fails to parse a message without headers", func() {
+		_, err := ParseRaw(strings.NewReader("no headers"))
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,203 @@
+// Package inbound receives the emails posted by the SendGrid Inbound
+// Parse webhook.
+//
+//	handler := inbound.NewHandler(func(ctx context.Context, email *inbound.InboundEmail) error {
+//		log.Printf("%s wrote: %s", email.From, email.Subject)
+//		return nil
+//	})
+//	http.Handle("/sendgrid/inbound", handler)
+package inbound
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	// The maximum size of a request body used when Handler.MaxBodySize
+	// is zero. SendGrid does not post emails larger than 30MB.
+	DefaultMaxBodySize = 30 << 20
+	// The memory used to buffer attachments when Handler.MaxMemory is
+	// zero. Larger attachments are stored in temporary files.
+	DefaultMaxMemory = 10 << 20
+)
+
+// Processes a single inbound email. The attachments can be opened until
+// the callback returns.
+type Callback func(ctx context.Context, email *InboundEmail) error
+
+// An http.Handler for the SendGrid Inbound Parse webhook. It parses both
+// the default and the raw MIME variants of the multipart form and calls
+// the callback with the email. It responds with 200 when the email is
+// processed, with 4xx when the request is invalid and with 500 when the
+// callback fails so that SendGrid retries the delivery later.
+type Handler struct {
+	// Callback - Processes every email
+	Callback Callback
+	// MaxBodySize - The maximum size of a request body in bytes.
+	// DefaultMaxBodySize is used when it is zero.
+	MaxBodySize int64
+	// MaxMemory - The memory used to buffer attachments in bytes.
+	// DefaultMaxMemory is used when it is zero.
+	MaxMemory int64
+}
+
+// Creates a new handler calling the callback for every email
+func NewHandler(callback Callback) *Handler {
+	return &Handler{Callback: callback}
+}
+
+func (handler *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	limit := handler.MaxBodySize
+	if limit <= 0 {
+		limit = DefaultMaxBodySize
+	}
+
+	memory := handler.MaxMemory
+	if memory <= 0 {
+		memory = DefaultMaxMemory
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, limit)
+	err := r.ParseMultipartForm(memory)
+
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+		return
+	case err != nil:
+		http.Error(w, "malformed form: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	email, err := parseForm(r)
+	if err != nil {
+		http.Error(w, "malformed email: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := handler.Callback(r.Context(), email); err != nil {
+		http.Error(w, "failed to process the email", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func parseForm(r *http.Request) (*InboundEmail, error) {
+	form := r.MultipartForm
+	value := func(name string) string {
+		if values := form.Value[name]; len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+
+	var email *InboundEmail
+	if raw := value("email"); raw != "" {
+		parsed, err := ParseRaw(strings.NewReader(raw))
+		if err != nil {
+			return nil, err
+		}
+		email = parsed
+	} else {
+		email = &InboundEmail{
+			Text: value("text"),
+			HTML: value("html"),
+		}
+
+		headers, err := parseHeaders(value("headers"))
+		if err != nil {
+			return nil, err
+		}
+		email.setHeaders(headers)
+		if subject := value("subject"); subject != "" {
+			email.Subject = subject
+		}
+
+		attachments, err := parseAttachments(form.File, value("attachment-info"))
+		if err != nil {
+			return nil, err
+		}
+		email.Attachments = attachments
+	}
+
+	email.SenderIP = value("sender_ip")
+	email.SPF = value("SPF")
+	email.DKIM = value("dkim")
+	email.SpamScore = value("spam_score")
+	email.SpamReport = value("spam_report")
+
+	if envelope := value("envelope"); envelope != "" {
+		if err := json.Unmarshal([]byte(envelope), &email.Envelope); err != nil {
+			return nil, err
+		}
+	}
+
+	if charsets := value("charsets"); charsets != "" {
+		if err := json.Unmarshal([]byte(charsets), &email.Charsets); err != nil {
+			return nil, err
+		}
+	}
+
+	return email, nil
+}
+
+// Collects the "attachment1", "attachment2", ... files in order
+func parseAttachments(files map[string][]*multipart.FileHeader, info string) ([]Attachment, error) {
+	var details map[string]struct {
+		Filename  string `json:"filename"`
+		Type      string `json:"type"`
+		ContentID string `json:"content-id"`
+	}
+	if info != "" {
+		if err := json.Unmarshal([]byte(info), &details); err != nil {
+			return nil, err
+		}
+	}
+
+	var attachments []Attachment
+	for index := 1; ; index++ {
+		name := "attachment" + strconv.Itoa(index)
+		headers := files[name]
+		if len(headers) == 0 {
+			return attachments, nil
+		}
+
+		file := headers[0]
+		attachment := Attachment{
+			Filename:    file.Filename,
+			ContentType: file.Header.Get("Content-Type"),
+			Size:        file.Size,
+			open: func() (io.ReadCloser, error) {
+				return file.Open()
+			},
+		}
+
+		if detail, ok := details[name]; ok {
+			if detail.Filename != "" {
+				attachment.Filename = detail.Filename
+			}
+			if detail.Type != "" {
+				attachment.ContentType = detail.Type
+			}
+			attachment.ContentID = detail.ContentID
+		}
+
+		attachments = append(attachments, attachment)
+	}
+}
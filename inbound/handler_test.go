@@ -0,0 +1,141 @@
+package inbound_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/svett/sendbit/inbound"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Handler", func() {
+	var (
+		handler  *Handler
+		received *InboundEmail
+		content  []byte
+		failure  error
+		recorder *httptest.ResponseRecorder
+	)
+
+	post := func(fields map[string]string, files map[string]string) {
+		body := &bytes.Buffer{}
+		form := multipart.NewWriter(body)
+		for name, value := range fields {
+			Expect(form.WriteField(name, value)).To(Succeed())
+		}
+		for name, value := range files {
+			file, err := form.CreateFormFile(name, name+".txt")
+			Expect(err).ToNot(HaveOccurred())
+			file.Write([]byte(value))
+		}
+		Expect(form.Close()).To(Succeed())
+
+		request := httptest.NewRequest(http.MethodPost, "/inbound", body)
+		request.Header.Set("Content-Type", form.FormDataContentType())
+		recorder = httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+	}
+
+	BeforeEach(func() {
+		received = nil
+		content = nil
+		failure = nil
+		handler = NewHandler(func(ctx context.Context, email *InboundEmail) error {
+			received = email
+			if len(email.Attachments) > 0 {
+				reader, err := email.Attachments[0].Open()
+				Expect(err).ToNot(HaveOccurred())
+				defer reader.Close()
+				content, err = io.ReadAll(reader)
+				Expect(err).ToNot(HaveOccurred())
+			}
+			return failure
+		})
+	})
+
+	It("parses the default form", func() {
+		post(map[string]string{
+			"headers":         "From: John Smith <j.smith@example.com>\nTo: support@example.org\nSubject: Hi\n",
+			"from":            "John Smith <j.smith@example.com>",
+			"to":              "support@example.org",
+			"subject":         "Hi there",
+			"text":            "Hello",
+			"html":            "<p>Hello</p>",
+			"envelope":        `{"to":["support@example.org"],"from":"j.smith@example.com"}`,
+			"sender_ip":       "192.0.2.1",
+			"SPF":             "pass",
+			"dkim":            "{@example.com : pass}",
+			"spam_score":      "0.1",
+			"charsets":        `{"to":"UTF-8","subject":"UTF-8","text":"iso-8859-1"}`,
+			"attachments":     "1",
+			"attachment-info": `{"attachment1":{"filename":"report.csv","type":"text/csv","content-id":"ii_1"}}`,
+		}, map[string]string{"attachment1": "a,b\n1,2\n"})
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(received.Raw).To(BeFalse())
+		Expect(received.From.Address).To(Equal("j.smith@example.com"))
+		Expect(received.To[0].Address).To(Equal("support@example.org"))
+		Expect(received.Subject).To(Equal("Hi there"))
+		Expect(received.Text).To(Equal("Hello"))
+		Expect(received.HTML).To(Equal("<p>Hello</p>"))
+		Expect(received.Envelope).To(Equal(Envelope{From: "j.smith@example.com", To: []string{"support@example.org"}}))
+		Expect(received.SenderIP).To(Equal("192.0.2.1"))
+		Expect(received.SPF).To(Equal("pass"))
+		Expect(received.DKIM).To(Equal("{@example.com : pass}"))
+		Expect(received.SpamScore).To(Equal("0.1"))
+		Expect(received.Charsets["text"]).To(Equal("iso-8859-1"))
+
+		Expect(received.Attachments).To(HaveLen(1))
+		Expect(received.Attachments[0].Filename).To(Equal("report.csv"))
+		Expect(received.Attachments[0].ContentType).To(Equal("text/csv"))
+		Expect(received.Attachments[0].ContentID).To(Equal("ii_1"))
+		Expect(string(content)).To(Equal("a,b\n1,2\n"))
+	})
+
+	It("parses the raw variant", func() {
+		post(map[string]string{
+			"email":    rawMessage,
+			"envelope": `{"to":["support@example.org"],"from":"j.smith@example.com"}`,
+			"SPF":      "pass",
+		}, nil)
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(received.Raw).To(BeTrue())
+		Expect(received.Text).To(Equal("Café au lait"))
+		Expect(received.SPF).To(Equal("pass"))
+		Expect(received.Envelope.From).To(Equal("j.smith@example.com"))
+		Expect(string(content)).To(Equal("hello world"))
+	})
+
+	Context("when the callback fails", func() {
+		It("responds with 500 so that SendGrid retries", func() {
+			failure = errors.New("queue is full")
+			post(map[string]string{"headers": "Subject: Hi\n"}, nil)
+			Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+		})
+	})
+
+	Context("when the body is too large", func() {
+		It("responds with 413", func() {
+			handler.MaxBodySize = 64
+			post(map[string]string{"text": string(make([]byte, 1024))}, nil)
+			Expect(recorder.Code).To(Equal(http.StatusRequestEntityTooLarge))
+			Expect(received).To(BeNil())
+		})
+	})
+
+	Context("when the body is not a form", func() {
+		It("responds with 400", func() {
+			recorder = httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/inbound", nil))
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+})
@@ -0,0 +1,192 @@
+package sendbit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	// The first delay between two polls of a job when Job.Interval is zero
+	DefaultJobInterval = time.Second
+	// The longest delay between two polls of a job
+	MaxJobInterval = 30 * time.Second
+)
+
+// Represents the state of an asynchronous v3 contacts job
+type JobStatus string
+
+const (
+	// The job is not processed yet
+	JobPending JobStatus = "pending"
+	// The job is processed
+	JobCompleted JobStatus = "completed"
+	// The job is processed but some contacts were rejected
+	JobErrored JobStatus = "errored"
+	// The job cannot be processed
+	JobFailed JobStatus = "failed"
+)
+
+// Counts the contacts processed by a job
+type JobResults struct {
+	// The number of the contacts sent
+	Requested int `json:"requested_count"`
+	// The number of the created contacts
+	Created int `json:"created_count"`
+	// The number of the updated contacts
+	Updated int `json:"updated_count"`
+	// The number of the deleted contacts
+	Deleted int `json:"deleted_count"`
+	// The number of the rejected contacts
+	Errored int `json:"errored_count"`
+	// The URL of a file describing the rejected contacts
+	ErrorsURL string `json:"errors_url"`
+}
+
+// Represents an asynchronous v3 contacts job such as the upsert sent by
+// AddRecipients or ImportCSV. SendGrid ingests the contacts after the
+// call has returned, so the job is waited on to know the outcome.
+type Job struct {
+	// The job identificator
+	ID string `json:"id"`
+	// The job type such as "upsert"
+	Type string `json:"job_type"`
+	// The job state
+	Status JobStatus `json:"status"`
+	// The contacts processed so far
+	Results JobResults `json:"results"`
+	// The time the processing started at
+	StartedAt time.Time `json:"started_at"`
+	// The time the processing finished at
+	FinishedAt time.Time `json:"finished_at"`
+	// Interval - The first delay between two polls. It is doubled after
+	// every poll up to MaxJobInterval. DefaultJobInterval is used when it
+	// is zero.
+	Interval time.Duration `json:"-"`
+	// Progress - Called with the job after every poll
+	Progress func(job *Job) `json:"-"`
+
+	client *Client
+}
+
+// Determines whether the job is processed
+func (job *Job) Done() bool {
+	return job.Status != "" && job.Status != JobPending
+}
+
+// Blocks until the job is processed or ctx is done. The job is polled
+// with an exponential backoff and a JobError is returned when it does
+// not complete.
+func (job *Job) Wait(ctx context.Context) error {
+	client := job.client.WithContext(ctx)
+	ctx, span := client.startSpan("Job.Wait")
+	defer span.End()
+
+	errorf := func(err error) error {
+		return client.errorf("Job.Wait", err)
+	}
+
+	interval := job.Interval
+	if interval <= 0 {
+		interval = DefaultJobInterval
+	}
+
+	for !job.Done() {
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			failSpan(ctx, ctx.Err())
+			return errorf(ctx.Err())
+		case <-timer.C:
+		}
+
+		if err := client.fetchJob(ctx, job); err != nil {
+			return errorf(err)
+		}
+
+		if job.Progress != nil {
+			job.Progress(job)
+		}
+
+		if interval *= 2; interval > MaxJobInterval {
+			interval = MaxJobInterval
+		}
+	}
+
+	if job.Status != JobCompleted {
+		err := &JobError{Job: job}
+		failSpan(ctx, err)
+		return errorf(err)
+	}
+
+	return nil
+}
+
+// Blocks until every job is processed or ctx is done. The first error
+// is returned.
+func WaitJobs(ctx context.Context, jobs []*Job) error {
+	for _, job := range jobs {
+		if err := job.Wait(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Represents a job that did not complete
+type JobError struct {
+	Job *Job
+}
+
+func (err *JobError) Error() string {
+	results := err.Job.Results
+	message := fmt.Sprintf("The job %s is %s: %d of %d contact(s) errored.",
+		err.Job.ID, err.Job.Status, results.Errored, results.Requested)
+	if results.ErrorsURL != "" {
+		message += " See " + results.ErrorsURL
+	}
+	return message
+}
+
+// Fetch the state of a v3 contacts job
+func (client *Client) Job(id string) (*Job, error) {
+	ctx, span := client.startSpan("Job")
+	defer span.End()
+
+	errorf := func(err error) error {
+		return client.errorf("Job", err)
+	}
+
+	if err := client.requireMarketing(); err != nil {
+		return nil, errorf(err)
+	}
+
+	if id == "" {
+		return nil, errorf(errors.New("The job id is empty."))
+	}
+
+	job := &Job{ID: id, client: client}
+	if err := client.fetchJob(ctx, job); err != nil {
+		return nil, errorf(err)
+	}
+
+	return job, nil
+}
+
+func (client *Client) newJob(id string) *Job {
+	return &Job{ID: id, Status: JobPending, client: client}
+}
+
+func (client *Client) fetchJob(ctx context.Context, job *Job) error {
+	response, err := client.request(ctx, http.MethodGet, "/marketing/contacts/imports/"+url.PathEscape(job.ID), nil)
+	if err != nil {
+		return err
+	}
+
+	return json.NewDecoder(response).Decode(job)
+}
@@ -0,0 +1,114 @@
+package sendbit_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	. "github.com/svett/sendbit"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Job", func() {
+	var (
+		fake   *FakeMarketing
+		client *Client
+		job    *Job
+	)
+
+	BeforeEach(func() {
+		fake = NewFakeMarketing()
+		fake.AddList("newsletter", Recipient{Email: "j.smith@example.com"})
+		fake.PendingPolls = 2
+		client = fake.Client()
+
+		result, err := client.AddRecipients("newsletter", []Recipient{
+			{Email: "j.smith@example.com"},
+			{Email: "m.freeman@example.com"},
+			{Email: "mike.t@example.com"},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Jobs).To(HaveLen(1))
+		job = result.Jobs[0]
+		job.Interval = time.Millisecond
+	})
+
+	AfterEach(func() {
+		fake.Close()
+	})
+
+	It("is returned pending by the v3 add calls", func() {
+		Expect(job.Status).To(Equal(JobPending))
+		Expect(job.Done()).To(BeFalse())
+	})
+
+	It("waits for the job to complete", func() {
+		statuses := []JobStatus{}
+		job.Progress = func(job *Job) {
+			statuses = append(statuses, job.Status)
+		}
+
+		Expect(job.Wait(context.Background())).To(Succeed())
+		Expect(statuses).To(Equal([]JobStatus{JobPending, JobPending, JobCompleted}))
+		Expect(job.Type).To(Equal("upsert"))
+		Expect(job.Results).To(Equal(JobResults{Requested: 2, Created: 2}))
+	})
+
+	It("backs off between the polls", func() {
+		polls := []time.Time{}
+		job.Progress = func(job *Job) {
+			polls = append(polls, time.Now())
+		}
+		job.Interval = 10 * time.Millisecond
+
+		Expect(job.Wait(context.Background())).To(Succeed())
+		Expect(polls).To(HaveLen(3))
+		Expect(polls[2].Sub(polls[1])).To(BeNumerically(">=", 20*time.Millisecond))
+	})
+
+	It("is fetched by its identificator", func() {
+		fetched, err := client.Job(job.ID)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fetched.ID).To(Equal(job.ID))
+		Expect(fetched.Results.Requested).To(Equal(2))
+	})
+
+	Context("when the job is errored", func() {
+		It("returns the error details", func() {
+			fake.Jobs[job.ID].Status = "errored"
+			fake.Jobs[job.ID].Results["errored_count"] = 1
+
+			err := job.Wait(context.Background())
+			var jobErr *JobError
+			Expect(errors.As(err, &jobErr)).To(BeTrue())
+			Expect(err).To(MatchError("sendbit: client.Job.Wait error: The job " + job.ID +
+				" is errored: 1 of 2 contact(s) errored."))
+		})
+	})
+
+	Context("when the context is done", func() {
+		It("stops waiting", func() {
+			fake.PendingPolls = 1000
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+
+			err := job.Wait(ctx)
+			Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+		})
+	})
+
+	Describe("ImportCSV", func() {
+		It("reports the jobs to wait on", func() {
+			report, err := client.ImportCSV("newsletter", strings.NewReader("email\nbob@example.com\n"), nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(report.Jobs).To(HaveLen(1))
+
+			report.Jobs[0].Interval = time.Millisecond
+			Expect(WaitJobs(context.Background(), report.Jobs)).To(Succeed())
+			Expect(report.Jobs[0].Status).To(Equal(JobCompleted))
+		})
+	})
+})
@@ -1,30 +1,85 @@
 package sendbit
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
-	"io"
-	"net/url"
 )
 
 // Determines whether a error is 'NotListExist' error.
 func IsListNotExist(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var missing *ListNotExistError
+	if errors.As(err, &missing) {
+		return true
+	}
+
+	// The v2 API reports the missing lists of the other calls in the
+	// message of its error
+	var response *responseError
+	if errors.As(err, &response) {
+		return isNotExistMessage(response.message)
+	}
+
 	var list string
 	count, err := fmt.Sscanf(err.Error(), "sendbit: client.List error: the title(s) %s do not exist", &list)
 	return list != "" && count == 1 && err == nil
 }
 
+// Determines whether a error is reported for a name shared by many lists
+func IsListAmbiguous(err error) bool {
+	var ambiguous *AmbiguousListError
+	return errors.As(err, &ambiguous)
+}
+
+// Reported when no list has a particular name
+type ListNotExistError struct {
+	// The list name
+	Name string
+}
+
+// The message matches the one of the v2 API, so the callers parsing it
+// keep working
+func (err *ListNotExistError) Error() string {
+	return fmt.Sprintf("the title(s) '%s' do not exist", err.Name)
+}
+
+// Reported when many lists have a particular name
+type AmbiguousListError struct {
+	// The list name
+	Name string
+	// The lists having the name
+	Lists []List
+}
+
+func (err *AmbiguousListError) Error() string {
+	return fmt.Sprintf("the title '%s' matches %d lists", err.Name, len(err.Lists))
+}
+
+func isNotExistMessage(message string) bool {
+	var list string
+	count, err := fmt.Sscanf(message, "the title(s) %s do not exist", &list)
+	return list != "" && count == 1 && err == nil
+}
+
 // Represents a Recipient List
 type List struct {
 	// The list identificator
 	ID uint64 `json:"id"`
 	// The list name
 	Name string `json:"list"`
+	// The list identificator of the v3 Marketing Campaigns API
+	UUID string `json:"uuid,omitempty"`
 }
 
 // Creates a new recipient list
 func (client *Client) CreateList(name string) error {
+	ctx, span := client.startSpan("CreateList")
+	defer span.End()
+
 	errorf := func(err error) error {
 		return client.errorf("CreateList", err)
 	}
@@ -33,11 +88,8 @@ func (client *Client) CreateList(name string) error {
 		return errorf(errors.New("The list name cannot be empty."))
 	}
 
-	data := url.Values{}
-	data.Add("list", name)
-
-	_, err := client.post("/newsletter/lists/add.json", data)
-	if err != nil {
+	defer client.invalidate(cacheKey("List", name))
+	if err := client.backend().createList(ctx, name); err != nil {
 		return errorf(err)
 	}
 
@@ -46,6 +98,9 @@ func (client *Client) CreateList(name string) error {
 
 // Remove a Recipient List from your account.
 func (client *Client) DeleteList(name string) error {
+	ctx, span := client.startSpan("DeleteList")
+	defer span.End()
+
 	errorf := func(err error) error {
 		return client.errorf("DeleteList", err)
 	}
@@ -54,18 +109,42 @@ func (client *Client) DeleteList(name string) error {
 		return errorf(errors.New("The list name cannot be empty."))
 	}
 
-	data := url.Values{}
-	data.Add("list", name)
-	_, err := client.post("/newsletter/lists/delete.json", data)
-	if err != nil {
+	defer client.invalidate(cacheKey("List", name), cacheKey("RecipientCount", name))
+	if err := client.backend().deleteList(ctx, name); err != nil {
+		return errorf(err)
+	}
+
+	return nil
+}
+
+// Rename a Recipient List
+func (client *Client) RenameList(name, newName string) error {
+	ctx, span := client.startSpan("RenameList")
+	defer span.End()
+
+	errorf := func(err error) error {
+		return client.errorf("RenameList", err)
+	}
+
+	if name == "" || newName == "" {
+		return errorf(errors.New("The list name cannot be empty."))
+	}
+
+	defer client.invalidate(cacheKey("List", name), cacheKey("RecipientCount", name),
+		cacheKey("List", newName), cacheKey("RecipientCount", newName))
+	if err := client.backend().renameList(ctx, name, newName); err != nil {
 		return errorf(err)
 	}
 
 	return nil
 }
 
-// List a recipient list
+// Get the recipient list with a particular name. ListNotExistError is
+// returned when no list has the name and AmbiguousListError when many do.
 func (client *Client) List(name string) (*List, error) {
+	ctx, span := client.startSpan("List")
+	defer span.End()
+
 	errorf := func(err error) error {
 		return client.errorf("List", err)
 	}
@@ -74,41 +153,95 @@ func (client *Client) List(name string) (*List, error) {
 		return nil, errorf(errors.New("The list name cannot be empty."))
 	}
 
-	data := url.Values{}
-	data.Add("list", name)
-
-	response, err := client.post("/newsletter/lists/get.json", data)
+	list, err := client.list(ctx, name)
 	if err != nil {
 		return nil, errorf(err)
 	}
 
-	var lists []List
-	if err := json.NewDecoder(response).Decode(&lists); err != nil && err != io.EOF {
-		return nil, errorf(err)
+	return list, nil
+}
+
+// Looks up a list through the cache
+func (client *Client) list(ctx context.Context, name string) (*List, error) {
+	value, err := client.cached(ctx, cacheKey("List", name), func(ctx context.Context) (interface{}, error) {
+		lists, err := client.backend().lists(ctx, []string{name})
+		if err != nil {
+			return nil, err
+		}
+
+		switch len(lists) {
+		case 0:
+			return nil, &ListNotExistError{Name: name}
+		case 1:
+			return &lists[0], nil
+		default:
+			return nil, &AmbiguousListError{Name: name, Lists: lists}
+		}
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	if len(lists) == 1 {
-		return &lists[0], nil
+	// The cached list is shared, so every caller gets its own copy
+	list := *value.(*List)
+	return &list, nil
+}
+
+// Determines whether a recipient list with a particular name exists
+func (client *Client) ExistsList(name string) (bool, error) {
+	ctx, span := client.startSpan("ExistsList")
+	defer span.End()
+
+	errorf := func(err error) error {
+		return client.errorf("ExistsList", err)
+	}
+
+	if name == "" {
+		return false, errorf(errors.New("The list name cannot be empty."))
 	}
 
-	return nil, nil
+	_, err := client.list(ctx, name)
+	switch {
+	case err == nil || IsListAmbiguous(err):
+		return true, nil
+	case IsListNotExist(err):
+		return false, nil
+	default:
+		return false, errorf(err)
+	}
 }
 
-// List all Recipient Lists on your account, or check if a particular List exists.
+// List all Recipient Lists on your account, or only the ones with
+// particular names. The names that no list has are skipped and the empty
+// names are ignored, so only empty names list every list.
 func (client *Client) Lists(names ...string) ([]List, error) {
-	errorf := func(err error) error {
-		return client.errorf("Lists", err)
+	unique := make([]string, 0, len(names))
+	seen := map[string]bool{}
+	for _, name := range names {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			unique = append(unique, name)
+		}
 	}
 
-	response, err := client.post("/newsletter/lists/get.json", nil)
-	if err != nil {
-		return nil, errorf(err)
+	if len(unique) == 0 {
+		return client.ListPager().All(client.context())
 	}
 
-	var lists []List
-	if err := json.NewDecoder(response).Decode(&lists); err != nil && err != io.EOF {
-		return nil, errorf(err)
+	ctx, span := client.startSpan("Lists")
+	defer span.End()
+
+	lists, err := client.backend().lists(ctx, unique)
+	if err != nil {
+		return nil, client.errorf("Lists", err)
 	}
 
 	return lists, nil
 }
+
+// Page through the Recipient Lists on your account
+func (client *Client) ListPager() *Pager[List] {
+	return newPager(client, "Lists", func(ctx context.Context, cursor string, size int) ([]List, string, error) {
+		return client.backend().listPage(ctx, cursor, size)
+	})
+}
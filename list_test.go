@@ -134,3 +134,173 @@ var _ = Describe("List", func() {
 			"title(s) '%s' do not exist", name)))
 	})
 })
+
+var _ = Describe("RenameList", func() {
+	var (
+		fake   *FakeSendGrid
+		client *Client
+	)
+
+	BeforeEach(func() {
+		fake = NewFakeSendGrid()
+		fake.AddList("news", Recipient{Email: "a@example.com"})
+		client = fake.Client()
+	})
+
+	AfterEach(func() {
+		fake.Close()
+	})
+
+	It("renames a list", func() {
+		Expect(client.RenameList("news", "newsletter")).To(Succeed())
+		Expect(fake.Names).To(Equal([]string{"newsletter"}))
+		Expect(fake.Recipients("newsletter")).To(HaveLen(1))
+	})
+
+	Context("when the new name is empty", func() {
+		It("fails to rename the list", func() {
+			Expect(client.RenameList("news", "")).To(
+				MatchError("sendbit: client.RenameList error: The list name cannot be empty."))
+		})
+	})
+})
+
+var _ = Describe("IsListNotExist", func() {
+	Context("when there is no error", func() {
+		It("returns false", func() {
+			Expect(IsListNotExist(nil)).To(Equal(false))
+		})
+	})
+
+	It("returns true for the typed error", func() {
+		err := fmt.Errorf("wrapped: %w", &ListNotExistError{Name: "mylist"})
+		Expect(IsListNotExist(err)).To(Equal(true))
+	})
+})
+
+var _ = Describe("Lists", func() {
+	Context("when the legacy API is used", func() {
+		var (
+			fake   *FakeSendGrid
+			client *Client
+		)
+
+		BeforeEach(func() {
+			fake = NewFakeSendGrid()
+			fake.AddList("news")
+			fake.AddList("offers")
+			fake.AddList("billing")
+			client = fake.Client()
+		})
+
+		AfterEach(func() {
+			fake.Close()
+		})
+
+		It("gets a list", func() {
+			list, err := client.List("offers")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(list.Name).To(Equal("offers"))
+		})
+
+		It("filters the lists by name on the server", func() {
+			lists, err := client.Lists("offers", "missing", "news", "offers")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lists).To(HaveLen(2))
+			Expect(lists[0].Name).To(Equal("offers"))
+			Expect(lists[1].Name).To(Equal("news"))
+			Expect(fake.Forms).To(HaveLen(3))
+			Expect(fake.Forms[0].Get("list")).To(Equal("offers"))
+		})
+
+		It("reports the missing list", func() {
+			list, err := client.List("missing")
+			Expect(list).To(BeNil())
+			Expect(IsListNotExist(err)).To(BeTrue())
+			Expect(err).To(MatchError("sendbit: client.List error: the title(s) 'missing' do not exist"))
+
+			var missing *ListNotExistError
+			Expect(errors.As(err, &missing)).To(BeTrue())
+			Expect(missing.Name).To(Equal("missing"))
+		})
+
+		It("determines whether a list exists", func() {
+			Expect(client.ExistsList("news")).To(BeTrue())
+			Expect(client.ExistsList("missing")).To(BeFalse())
+		})
+
+		Context("when the request fails", func() {
+			It("fails to determine whether a list exists", func() {
+				fake.FailOnce("/newsletter/lists/get.json", "internal error")
+				exists, err := client.ExistsList("news")
+				Expect(exists).To(BeFalse())
+				Expect(err).To(MatchError("sendbit: client.ExistsList error: internal error"))
+			})
+		})
+
+		It("lists every list for an empty filter", func() {
+			lists, err := client.Lists("")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lists).To(HaveLen(3))
+		})
+	})
+
+	Context("when the v3 API is used", func() {
+		var (
+			fake   *FakeMarketing
+			client *Client
+		)
+
+		BeforeEach(func() {
+			fake = NewFakeMarketing()
+			fake.AddList("news")
+			fake.AddList("offers")
+			client = fake.Client()
+		})
+
+		AfterEach(func() {
+			fake.Close()
+		})
+
+		It("filters the lists by name", func() {
+			lists, err := client.Lists("offers", "missing")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lists).To(HaveLen(1))
+			Expect(lists[0].Name).To(Equal("offers"))
+		})
+
+		It("reports the missing list", func() {
+			_, err := client.List("missing")
+			Expect(IsListNotExist(err)).To(BeTrue())
+			Expect(client.ExistsList("missing")).To(BeFalse())
+		})
+
+		Context("when many lists have the name", func() {
+			BeforeEach(func() {
+				fake.AddList("offers")
+			})
+
+			It("reports the ambiguous name", func() {
+				list, err := client.List("offers")
+				Expect(list).To(BeNil())
+				Expect(IsListAmbiguous(err)).To(BeTrue())
+				Expect(IsListNotExist(err)).To(BeFalse())
+				Expect(err).To(MatchError("sendbit: client.List error: the title 'offers' matches 2 lists"))
+
+				var ambiguous *AmbiguousListError
+				Expect(errors.As(err, &ambiguous)).To(BeTrue())
+				Expect(ambiguous.Lists).To(HaveLen(2))
+			})
+
+			It("considers the list existing", func() {
+				Expect(client.ExistsList("offers")).To(BeTrue())
+			})
+
+			It("returns every match", func() {
+				lists, err := client.Lists("offers")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(lists).To(HaveLen(2))
+			})
+		})
+	})
+})
@@ -0,0 +1,667 @@
+package sendbit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The maximum number of emails looked up by a single v3 search request
+const maxSearchEmails = 100
+
+// The maximum page size of the v3 list and contact endpoints
+const maxPageSize = 1000
+
+// A list of the v3 Marketing Campaigns API
+type marketingList struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// A contact of the v3 Marketing Campaigns API
+type marketingContact struct {
+	ID           string                 `json:"id,omitempty"`
+	Email        string                 `json:"email"`
+	FirstName    string                 `json:"first_name,omitempty"`
+	LastName     string                 `json:"last_name,omitempty"`
+	ListIDs      []string               `json:"list_ids,omitempty"`
+	CustomFields map[string]interface{} `json:"custom_fields,omitempty"`
+}
+
+func (contact *marketingContact) recipient() Recipient {
+	recipient := Recipient{
+		Email: contact.Email,
+		Name:  strings.TrimSpace(contact.FirstName + " " + contact.LastName),
+	}
+
+	for name, value := range contact.CustomFields {
+		if value == nil {
+			continue
+		}
+		if recipient.Fields == nil {
+			recipient.Fields = map[string]string{}
+		}
+		recipient.Fields[name] = fieldText(value)
+	}
+	return recipient
+}
+
+// Formats a custom field value of a contact as FieldDefinition.value
+// writes it. The numbers are never formatted in the exponent notation
+// and the dates that come back as timestamps are formatted in the first
+// of the DateFieldLayouts.
+func fieldText(value interface{}) string {
+	switch value := value.(type) {
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case string:
+		if date, err := time.Parse(time.RFC3339, value); err == nil {
+			return date.Format(DateFieldLayouts[0])
+		}
+		return value
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+func (contact *marketingContact) member(listID string) bool {
+	for _, id := range contact.ListIDs {
+		if id == listID {
+			return true
+		}
+	}
+	return false
+}
+
+// The v3 Marketing Campaigns API backend. The lists are addressed by
+// name as the legacy ones are and are resolved to their identifiers once
+// per client call. The custom fields of the recipients are checked against
+// their definitions and are sent keyed by the field identifiers.
+type marketingBackend struct {
+	client *Client
+	// The custom field definitions keyed by name and by identificator.
+	// They are fetched once per backend.
+	definitions map[string]*FieldDefinition
+	// The lists of the account. They are fetched once per backend and
+	// dropped when a list is created, deleted or renamed.
+	accountLists []marketingList
+}
+
+func (backend *marketingBackend) checkRecipients(ctx context.Context, recipients []Recipient) error {
+	for _, recipient := range recipients {
+		if _, err := backend.contact(ctx, recipient); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Converts a recipient to a contact. The name is split into the first
+// and the last name at the first space.
+func (backend *marketingBackend) contact(ctx context.Context, recipient Recipient) (marketingContact, error) {
+	contact := marketingContact{Email: recipient.Email}
+	names := strings.SplitN(strings.TrimSpace(recipient.Name), " ", 2)
+	contact.FirstName = names[0]
+	if len(names) == 2 {
+		contact.LastName = names[1]
+	}
+
+	if len(recipient.Fields) == 0 {
+		return contact, nil
+	}
+
+	if err := backend.loadDefinitions(ctx); err != nil {
+		return contact, err
+	}
+
+	contact.CustomFields = make(map[string]interface{}, len(recipient.Fields))
+	for name, text := range recipient.Fields {
+		invalid := &InvalidFieldError{Email: recipient.Email, Field: name}
+		definition, ok := backend.definitions[name]
+		if !ok {
+			invalid.Problem = "the field is not defined"
+			return contact, invalid
+		}
+
+		value, err := definition.value(text)
+		if err != nil {
+			invalid.Problem = err.Error()
+			return contact, invalid
+		}
+		if value == nil {
+			continue
+		}
+		contact.CustomFields[definition.ID] = value
+	}
+	return contact, nil
+}
+
+// Fetches the custom field definitions unless they are fetched already
+func (backend *marketingBackend) loadDefinitions(ctx context.Context) error {
+	if backend.definitions != nil {
+		return nil
+	}
+
+	definitions, err := backend.client.fieldDefinitions(ctx)
+	if err != nil {
+		return err
+	}
+
+	backend.definitions = make(map[string]*FieldDefinition, 2*len(definitions))
+	for index := range definitions {
+		backend.definitions[definitions[index].Name] = &definitions[index]
+		backend.definitions[definitions[index].ID] = &definitions[index]
+	}
+	return nil
+}
+
+func (backend *marketingBackend) createList(ctx context.Context, name string) error {
+	lists, err := backend.allLists(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, list := range lists {
+		if list.Name == name {
+			return fmt.Errorf("%s already exists", name)
+		}
+	}
+
+	backend.accountLists = nil
+	_, err = backend.client.request(ctx, http.MethodPost, "/marketing/lists", map[string]string{"name": name})
+	return err
+}
+
+func (backend *marketingBackend) deleteList(ctx context.Context, name string) error {
+	list, err := backend.find(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	backend.accountLists = nil
+	_, err = backend.client.request(ctx, http.MethodDelete, "/marketing/lists/"+url.PathEscape(list.ID), nil)
+	return err
+}
+
+func (backend *marketingBackend) renameList(ctx context.Context, name, newName string) error {
+	list, err := backend.find(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	backend.accountLists = nil
+	_, err = backend.client.request(ctx, http.MethodPatch, "/marketing/lists/"+url.PathEscape(list.ID),
+		map[string]string{"name": newName})
+	return err
+}
+
+func (backend *marketingBackend) listPage(ctx context.Context, cursor string, size int) ([]List, string, error) {
+	found, next, err := backend.fetchLists(ctx, cursor, size)
+	if err != nil {
+		return nil, "", err
+	}
+
+	lists := make([]List, len(found))
+	for index, list := range found {
+		lists[index] = List{UUID: list.ID, Name: list.Name}
+	}
+	return lists, next, nil
+}
+
+// The v3 API does not filter the lists by name, so they are filtered
+// while they are paged
+func (backend *marketingBackend) lists(ctx context.Context, names []string) ([]List, error) {
+	found, err := backend.allLists(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	named := map[string][]List{}
+	for _, list := range found {
+		named[list.Name] = append(named[list.Name], List{UUID: list.ID, Name: list.Name})
+	}
+
+	lists := []List{}
+	for _, name := range names {
+		lists = append(lists, named[name]...)
+	}
+	return lists, nil
+}
+
+func (backend *marketingBackend) addRecipients(ctx context.Context, list string, recipients []Recipient) (int, *Job, error) {
+	found, err := backend.find(ctx, list)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	existing, err := backend.contacts(ctx, emails(recipients))
+	if err != nil {
+		return 0, nil, err
+	}
+
+	contacts := []marketingContact{}
+	for _, recipient := range recipients {
+		if contact, ok := existing[strings.ToLower(recipient.Email)]; ok && contact.member(found.ID) {
+			continue
+		}
+
+		contact, err := backend.contact(ctx, recipient)
+		if err != nil {
+			return 0, nil, err
+		}
+		contacts = append(contacts, contact)
+	}
+
+	if len(contacts) == 0 {
+		return 0, nil, nil
+	}
+
+	job, err := backend.putContacts(ctx, found.ID, contacts)
+	if err != nil {
+		return 0, nil, err
+	}
+	return len(contacts), job, nil
+}
+
+// A contact sent by an update. The names are sent even when they are
+// empty, so that a removed name is cleared in the contact.
+type contactUpdate struct {
+	ID           string                 `json:"id,omitempty"`
+	Email        string                 `json:"email"`
+	FirstName    string                 `json:"first_name"`
+	LastName     string                 `json:"last_name"`
+	ListIDs      []string               `json:"list_ids,omitempty"`
+	CustomFields map[string]interface{} `json:"custom_fields,omitempty"`
+}
+
+// The contacts are upserted, so the recipient is updated in place. The
+// custom fields of the previous recipient that are missing or empty in
+// the updated one are sent as nulls, so that they are removed.
+func (backend *marketingBackend) updateRecipient(ctx context.Context, list string, previous, recipient *Recipient) (*Job, error) {
+	found, err := backend.find(ctx, list)
+	if err != nil {
+		return nil, err
+	}
+
+	contact, err := backend.contact(ctx, *recipient)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(previous.Fields) > 0 {
+		if err := backend.loadDefinitions(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	for name := range previous.Fields {
+		definition, ok := backend.definitions[name]
+		if !ok {
+			continue
+		}
+		if _, ok := contact.CustomFields[definition.ID]; ok {
+			continue
+		}
+		if contact.CustomFields == nil {
+			contact.CustomFields = map[string]interface{}{}
+		}
+		contact.CustomFields[definition.ID] = nil
+	}
+
+	return backend.putContacts(ctx, found.ID, []contactUpdate{contactUpdate(contact)})
+}
+
+// Upserts the contacts into a list and returns the job ingesting them
+func (backend *marketingBackend) putContacts(ctx context.Context, listID string, contacts interface{}) (*Job, error) {
+	body := map[string]interface{}{
+		"list_ids": []string{listID},
+		"contacts": contacts,
+	}
+	response, err := backend.client.request(ctx, http.MethodPut, "/marketing/contacts", body)
+	if err != nil {
+		return nil, err
+	}
+
+	var accepted struct {
+		JobID string `json:"job_id"`
+	}
+	if err := json.NewDecoder(response).Decode(&accepted); err != nil {
+		return nil, err
+	}
+	return backend.client.newJob(accepted.JobID), nil
+}
+
+func (backend *marketingBackend) deleteRecipients(ctx context.Context, list string, emails []string) (int, error) {
+	found, err := backend.find(ctx, list)
+	if err != nil {
+		return 0, err
+	}
+
+	existing, err := backend.contacts(ctx, emails)
+	if err != nil {
+		return 0, err
+	}
+
+	ids := []string{}
+	for _, email := range emails {
+		if contact, ok := existing[strings.ToLower(email)]; ok && contact.member(found.ID) {
+			ids = append(ids, contact.ID)
+			delete(existing, strings.ToLower(email))
+		}
+	}
+
+	removed := 0
+	for start := 0; start < len(ids); start += maxSearchEmails {
+		batch := ids[start:min(start+maxSearchEmails, len(ids))]
+		query := url.Values{}
+		query.Set("contact_ids", strings.Join(batch, ","))
+
+		path := fmt.Sprintf("/marketing/lists/%s/contacts?%s", url.PathEscape(found.ID), query.Encode())
+		if _, err := backend.client.request(ctx, http.MethodDelete, path, nil); err != nil {
+			return removed, err
+		}
+		removed += len(batch)
+	}
+	return removed, nil
+}
+
+// Deletes the contacts with particular emails from the account and
+// returns the number of the deleted contacts. The emails that are not
+// contacts are skipped.
+func (backend *marketingBackend) deleteContacts(ctx context.Context, emails []string) (int, error) {
+	existing, err := backend.contacts(ctx, emails)
+	if err != nil {
+		return 0, err
+	}
+
+	ids := make([]string, 0, len(existing))
+	for _, contact := range existing {
+		ids = append(ids, contact.ID)
+	}
+	sort.Strings(ids)
+
+	deleted := 0
+	for start := 0; start < len(ids); start += maxSearchEmails {
+		batch := ids[start:min(start+maxSearchEmails, len(ids))]
+		query := url.Values{}
+		query.Set("ids", strings.Join(batch, ","))
+
+		if _, err := backend.client.request(ctx, http.MethodDelete, "/marketing/contacts?"+query.Encode(), nil); err != nil {
+			return deleted, err
+		}
+		deleted += len(batch)
+	}
+	return deleted, nil
+}
+
+func (backend *marketingBackend) recipients(ctx context.Context, list, email string) ([]Recipient, error) {
+	found, err := backend.find(ctx, list)
+	if err != nil {
+		return nil, err
+	}
+
+	recipients := []Recipient{}
+	if email != "" {
+		existing, err := backend.contacts(ctx, []string{email})
+		if err != nil {
+			return nil, err
+		}
+
+		if contact, ok := existing[strings.ToLower(email)]; ok && contact.member(found.ID) {
+			recipients = append(recipients, contact.recipient())
+		}
+		return recipients, nil
+	}
+
+	for cursor := ""; ; {
+		page, next, err := backend.searchContacts(ctx, found.ID, cursor, maxPageSize)
+		if err != nil {
+			return nil, err
+		}
+		recipients = append(recipients, page...)
+
+		if next == "" {
+			return recipients, nil
+		}
+		cursor = next
+	}
+}
+
+func (backend *marketingBackend) recipientPage(ctx context.Context, list, cursor string, size int) ([]Recipient, string, error) {
+	found, err := backend.find(ctx, list)
+	if err != nil {
+		return nil, "", err
+	}
+	return backend.searchContacts(ctx, found.ID, cursor, size)
+}
+
+// Returns a page of the contacts of a list
+func (backend *marketingBackend) searchContacts(ctx context.Context, id, cursor string, size int) ([]Recipient, string, error) {
+	query := map[string]string{
+		"query": fmt.Sprintf("CONTAINS(list_ids, '%s')", strings.ReplaceAll(id, "'", "''")),
+	}
+	response, err := backend.client.request(ctx, http.MethodPost,
+		"/marketing/contacts/search?"+pageQuery(cursor, size).Encode(), query)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var page struct {
+		Result   []marketingContact `json:"result"`
+		Metadata pageMetadata       `json:"_metadata"`
+	}
+	if err := json.NewDecoder(response).Decode(&page); err != nil {
+		return nil, "", err
+	}
+
+	recipients := make([]Recipient, len(page.Result))
+	for index := range page.Result {
+		recipients[index] = page.Result[index].recipient()
+	}
+
+	next, err := page.Metadata.cursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	return recipients, next, nil
+}
+
+func (backend *marketingBackend) recipientCount(ctx context.Context, list string) (uint64, error) {
+	found, err := backend.find(ctx, list)
+	if err != nil {
+		return 0, err
+	}
+
+	response, err := backend.client.request(ctx, http.MethodGet,
+		fmt.Sprintf("/marketing/lists/%s/contacts/count", url.PathEscape(found.ID)), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var stats struct {
+		Count uint64 `json:"contact_count"`
+	}
+	if err := json.NewDecoder(response).Decode(&stats); err != nil {
+		return 0, err
+	}
+	return stats.Count, nil
+}
+
+// Returns every list of the account following the page tokens
+func (backend *marketingBackend) allLists(ctx context.Context) ([]marketingList, error) {
+	if backend.accountLists != nil {
+		return backend.accountLists, nil
+	}
+
+	lists := []marketingList{}
+	for cursor := ""; ; {
+		page, next, err := backend.fetchLists(ctx, cursor, maxPageSize)
+		if err != nil {
+			return nil, err
+		}
+		lists = append(lists, page...)
+
+		if next == "" {
+			backend.accountLists = lists
+			return lists, nil
+		}
+		cursor = next
+	}
+}
+
+// Returns a page of the lists of the account
+func (backend *marketingBackend) fetchLists(ctx context.Context, cursor string, size int) ([]marketingList, string, error) {
+	response, err := backend.client.request(ctx, http.MethodGet, "/marketing/lists?"+pageQuery(cursor, size).Encode(), nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var page struct {
+		Result   []marketingList `json:"result"`
+		Metadata pageMetadata    `json:"_metadata"`
+	}
+	if err := json.NewDecoder(response).Decode(&page); err != nil {
+		return nil, "", err
+	}
+
+	next, err := page.Metadata.cursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	return page.Result, next, nil
+}
+
+// The paging metadata of the v3 list endpoints
+type pageMetadata struct {
+	// The URL of the next page
+	Next string `json:"next"`
+}
+
+// Returns the page token of the next page, or an empty one when the
+// page is the last one or the token does not advance
+func (metadata *pageMetadata) cursor(current string) (string, error) {
+	if metadata.Next == "" {
+		return "", nil
+	}
+
+	next, err := url.Parse(metadata.Next)
+	if err != nil {
+		return "", err
+	}
+
+	token := next.Query().Get("page_token")
+	if token == current {
+		return "", nil
+	}
+	return token, nil
+}
+
+// Builds the query of a v3 page request
+func pageQuery(cursor string, size int) url.Values {
+	query := url.Values{}
+	query.Set("page_size", strconv.Itoa(min(size, maxPageSize)))
+	if cursor != "" {
+		query.Set("page_token", cursor)
+	}
+	return query
+}
+
+// Resolves a list name to the list
+func (backend *marketingBackend) find(ctx context.Context, name string) (*marketingList, error) {
+	lists, err := backend.allLists(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for index := range lists {
+		if lists[index].Name == name {
+			return &lists[index], nil
+		}
+	}
+	return nil, notExistList(name)
+}
+
+// Returns the memberships of an email. The lists come from the list
+// identifiers of its contact, so a single search finds them.
+func (backend *marketingBackend) memberships(ctx context.Context, email string) ([]Membership, error) {
+	existing, err := backend.contacts(ctx, []string{email})
+	if err != nil {
+		return nil, err
+	}
+
+	contact, ok := existing[strings.ToLower(email)]
+	if !ok {
+		return nil, nil
+	}
+
+	lists, err := backend.allLists(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var memberships []Membership
+	for _, list := range lists {
+		if contact.member(list.ID) {
+			memberships = append(memberships, Membership{
+				List:      List{UUID: list.ID, Name: list.Name},
+				Recipient: contact.recipient(),
+			})
+		}
+	}
+	return memberships, nil
+}
+
+// Returns the contacts of the account with particular emails keyed by
+// the lower cased email. The emails that are not contacts are omitted.
+func (backend *marketingBackend) contacts(ctx context.Context, emails []string) (map[string]*marketingContact, error) {
+	contacts := map[string]*marketingContact{}
+	for start := 0; start < len(emails); start += maxSearchEmails {
+		body := map[string][]string{"emails": emails[start:min(start+maxSearchEmails, len(emails))]}
+		// The search responds with 404 when none of the emails is found
+		response, err := backend.client.sendJSON(ctx, http.MethodPost, "/marketing/contacts/search/emails", body)
+		if hasStatus(err, http.StatusNotFound) {
+			continue
+		}
+		if err != nil {
+			failSpan(ctx, err)
+			return nil, err
+		}
+
+		var result struct {
+			Result map[string]struct {
+				Contact *marketingContact `json:"contact"`
+			} `json:"result"`
+		}
+		if err := json.NewDecoder(response).Decode(&result); err != nil {
+			return nil, err
+		}
+
+		for email, item := range result.Result {
+			if item.Contact != nil {
+				contacts[strings.ToLower(email)] = item.Contact
+			}
+		}
+	}
+	return contacts, nil
+}
+
+// Fails the calls that exist only in the v3 Marketing Campaigns API
+func (client *Client) requireMarketing() error {
+	if client.API != MarketingAPI {
+		return errors.New("The call requires the v3 Marketing Campaigns API.")
+	}
+	return nil
+}
+
+// Returns the error reported for a missing list, so IsListNotExist holds
+// for both API generations
+func notExistList(name string) error {
+	return &ListNotExistError{Name: name}
+}
@@ -0,0 +1,187 @@
+package sendbit_test
+
+import (
+	"sort"
+
+	. "github.com/svett/sendbit"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MarketingAPI", func() {
+	var (
+		fake   *FakeMarketing
+		client *Client
+		id     string
+	)
+
+	BeforeEach(func() {
+		fake = NewFakeMarketing()
+		id = fake.AddList("newsletter",
+			Recipient{Name: "John Smith", Email: "j.smith@example.com", Fields: map[string]string{"plan": "pro"}},
+			Recipient{Name: "Mike", Email: "mike.t@example.com"},
+		)
+		client = fake.Client()
+	})
+
+	AfterEach(func() {
+		fake.Close()
+	})
+
+	It("is selected by a constructor option", func() {
+		Expect(client.API).To(Equal(MarketingAPI))
+	})
+
+	Describe("lists", func() {
+		It("creates, renames and deletes a list", func() {
+			Expect(client.CreateList("offers")).To(Succeed())
+			Expect(client.RenameList("offers", "deals")).To(Succeed())
+
+			list, err := client.List("deals")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(list.Name).To(Equal("deals"))
+			Expect(list.UUID).ToNot(BeEmpty())
+
+			Expect(client.DeleteList("deals")).To(Succeed())
+			lists, err := client.Lists()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lists).To(Equal([]List{{UUID: id, Name: "newsletter"}}))
+		})
+
+		It("follows the page tokens", func() {
+			fake.PageSize = 2
+			fake.AddList("offers")
+			fake.AddList("billing")
+
+			lists, err := client.Lists()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lists).To(HaveLen(3))
+			Expect(lists[2].Name).To(Equal("billing"))
+		})
+
+		Context("when the list is duplicated", func() {
+			It("fails to create it", func() {
+				err := client.CreateList("newsletter")
+				Expect(err).To(MatchError("sendbit: client.CreateList error: newsletter already exists"))
+			})
+		})
+
+		Context("when the list does not exist", func() {
+			It("reports it as the legacy API does", func() {
+				_, err := client.List("unknown")
+				Expect(IsListNotExist(err)).To(BeTrue())
+
+				err = client.DeleteList("unknown")
+				Expect(err).To(MatchError("sendbit: client.DeleteList error: the title(s) 'unknown' do not exist"))
+			})
+		})
+	})
+
+	Describe("recipients", func() {
+		It("returns the recipients of a list", func() {
+			recipients, err := client.Recipients("newsletter")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(recipients).To(Equal([]Recipient{
+				{Name: "John Smith", Email: "j.smith@example.com", Fields: map[string]string{"plan": "pro"}},
+				{Name: "Mike", Email: "mike.t@example.com"},
+			}))
+
+			recipient, err := client.Recipient("newsletter", "mike.t@example.com")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(recipient).To(Equal(&Recipient{Name: "Mike", Email: "mike.t@example.com"}))
+
+			count, err := client.RecipientCount("newsletter")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(count).To(Equal(uint64(2)))
+		})
+
+		It("formats the numbers and the dates of the custom fields", func() {
+			fake.Contacts["mike.t@example.com"].CustomFields = map[string]interface{}{
+				"score":  1234567,
+				"joined": "2024-03-01T00:00:00Z",
+			}
+
+			recipient, err := client.Recipient("newsletter", "mike.t@example.com")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(recipient.Fields).To(Equal(map[string]string{"score": "1234567", "joined": "03/01/2024"}))
+		})
+
+		It("adds a recipient", func() {
+			recipient := &Recipient{Name: "Morgan Freeman", Email: "m.freeman@example.com"}
+			Expect(client.AddRecipient("newsletter", recipient)).To(Succeed())
+			Expect(fake.Contacts["m.freeman@example.com"].FirstName).To(Equal("Morgan"))
+			Expect(fake.Contacts["m.freeman@example.com"].LastName).To(Equal("Freeman"))
+
+			err := client.AddRecipient("newsletter", recipient)
+			Expect(IsRecipientExist(err)).To(BeTrue())
+		})
+
+		It("adds a contact of another list", func() {
+			other := fake.AddList("offers")
+			Expect(client.AddRecipient("offers", &Recipient{Email: "mike.t@example.com"})).To(Succeed())
+			Expect(fake.Members(other)).To(Equal([]string{"mike.t@example.com"}))
+		})
+
+		It("adds many recipients", func() {
+			result, err := client.AddRecipients("newsletter", []Recipient{
+				{Email: "j.smith@example.com"},
+				{Email: "m.freeman@example.com"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Inserted).To(Equal(1))
+			Expect(result.Existing).To(Equal(1))
+		})
+
+		It("removes recipients", func() {
+			Expect(client.DeleteRecipient("newsletter", "mike.t@example.com")).To(Succeed())
+
+			removed, err := client.DeleteRecipients("newsletter", []string{"j.smith@example.com", "nobody@example.com"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(removed).To(Equal(1))
+			Expect(fake.Members(id)).To(BeEmpty())
+			Expect(fake.Contacts).To(HaveLen(2))
+		})
+
+		It("runs the composite calls", func() {
+			report, err := client.SyncList("newsletter", []Recipient{
+				{Name: "John Smith", Email: "j.smith@example.com", Fields: map[string]string{"plan": "pro"}},
+				{Email: "m.freeman@example.com"},
+			}, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(report.Added).To(Equal(1))
+			Expect(report.Removed).To(Equal(1))
+
+			members := fake.Members(id)
+			sort.Strings(members)
+			Expect(members).To(Equal([]string{"j.smith@example.com", "m.freeman@example.com"}))
+		})
+	})
+
+	Context("when many recipients are updated", func() {
+		It("resolves the list once per call", func() {
+			_, err := client.SyncList("newsletter", []Recipient{
+				{Name: "Johnny Smith", Email: "j.smith@example.com"},
+				{Name: "Mike Tyson", Email: "mike.t@example.com"},
+			}, nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			count := 0
+			for _, request := range fake.Requests {
+				if request == "GET /v3/marketing/lists" {
+					count++
+				}
+			}
+			// Once to read the recipients and once to update both of them
+			Expect(count).To(Equal(2))
+		})
+	})
+
+	Context("when the API key is wrong", func() {
+		It("returns the API error", func() {
+			client.Auth.Password = "wrong"
+			_, err := client.Lists()
+			Expect(err).To(MatchError("sendbit: client.Lists error: authorization required"))
+		})
+	})
+})
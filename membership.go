@@ -0,0 +1,122 @@
+package sendbit
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Represents the failures of a call fanned out over many lists.
+// The keys are the list names.
+type ListErrors map[string]error
+
+func (errs ListErrors) Error() string {
+	names := make([]string, 0, len(errs))
+	for name := range errs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	messages := make([]string, len(names))
+	for index, name := range names {
+		messages[index] = fmt.Sprintf("%s: %s", name, errs[name])
+	}
+	return fmt.Sprintf("The call failed for %d list(s): %s", len(errs),
+		strings.Join(messages, "; "))
+}
+
+// Represents a recipient subscribed to a particular list
+type Membership struct {
+	// The list the recipient is subscribed to
+	List List
+	// The recipient as stored in the list
+	Recipient Recipient
+}
+
+// Lookup the lists an email is subscribed to. The email is normalized
+// like the added ones, without the role and disposable checks. The lists
+// are queried concurrently with at most Client.Concurrency requests at a time.
+// The memberships found are returned along with a ListErrors error
+// when some of the lists cannot be queried. With the v3 Marketing
+// Campaigns API the lists are read from the contact of the email instead.
+func (client *Client) ListsForEmail(email string) ([]Membership, error) {
+	ctx, span := client.startSpan("ListsForEmail")
+	defer span.End()
+
+	errorf := func(err error) error {
+		return client.errorf("ListsForEmail", err)
+	}
+
+	if email == "" {
+		return nil, errorf(errors.New("The email is empty."))
+	}
+
+	email, err := normalizeEmail(email)
+	if err != nil {
+		return nil, errorf(err)
+	}
+
+	if client.API == MarketingAPI {
+		backend := &marketingBackend{client: client}
+		memberships, err := backend.memberships(ctx, email)
+		if err != nil {
+			return nil, errorf(err)
+		}
+		return memberships, nil
+	}
+
+	inner := client.WithContext(ctx)
+	lists, err := inner.Lists()
+	if err != nil {
+		return nil, err
+	}
+
+	recipients := make([]*Recipient, len(lists))
+	failures := make([]error, len(lists))
+	inner.fanOut(len(lists), func(index int) {
+		recipients[index], failures[index] = inner.Recipient(lists[index].Name, email)
+	})
+
+	var memberships []Membership
+	errs := ListErrors{}
+	for index, list := range lists {
+		if failures[index] != nil {
+			errs[list.Name] = failures[index]
+		} else if recipients[index] != nil {
+			memberships = append(memberships, Membership{List: list, Recipient: *recipients[index]})
+		}
+	}
+
+	if len(errs) > 0 {
+		failSpan(ctx, errs)
+		return memberships, errorf(errs)
+	}
+
+	return memberships, nil
+}
+
+// Calls a function for every index in [0, count) with at most
+// Client.Concurrency calls running at a time
+func (client *Client) fanOut(count int, call func(index int)) {
+	concurrency := client.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+
+	slots := make(chan struct{}, concurrency)
+	group := sync.WaitGroup{}
+	for index := 0; index < count; index++ {
+		group.Add(1)
+		slots <- struct{}{}
+		go func(index int) {
+			defer func() {
+				<-slots
+				group.Done()
+			}()
+			call(index)
+		}(index)
+	}
+	group.Wait()
+}
@@ -0,0 +1,137 @@
+package sendbit_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	. "github.com/svett/sendbit"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ListsForEmail", func() {
+	var (
+		fake   *FakeSendGrid
+		client *Client
+	)
+	const email = "alice@example.com"
+
+	BeforeEach(func() {
+		fake = NewFakeSendGrid()
+		fake.AddList("news", Recipient{Name: "Alice", Email: email})
+		fake.AddList("offers", Recipient{Email: "bob@example.com"})
+		fake.AddList("billing", Recipient{Name: "Alice B.", Email: email})
+		client = fake.Client()
+	})
+
+	AfterEach(func() {
+		fake.Close()
+	})
+
+	It("returns the lists the email is subscribed to", func() {
+		memberships, err := client.ListsForEmail(email)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(memberships).To(Equal([]Membership{
+			{List: List{ID: 1, Name: "news"}, Recipient: Recipient{Name: "Alice", Email: email}},
+			{List: List{ID: 3, Name: "billing"}, Recipient: Recipient{Name: "Alice B.", Email: email}},
+		}))
+	})
+
+	It("limits the concurrent requests", func() {
+		for index := 0; index < 10; index++ {
+			fake.AddList(RandomString(8))
+		}
+
+		var inFlight, peak int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			current := atomic.AddInt32(&inFlight, 1)
+			defer atomic.AddInt32(&inFlight, -1)
+			for {
+				max := atomic.LoadInt32(&peak)
+				if current <= max || atomic.CompareAndSwapInt32(&peak, max, current) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			fake.Config.Handler.ServeHTTP(w, r)
+		}))
+		defer server.Close()
+
+		client.Host = server.URL
+		client.Concurrency = 2
+		_, err := client.ListsForEmail(email)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(peak).To(BeNumerically("<=", 2))
+	})
+
+	Context("when some lists cannot be queried", func() {
+		It("reports the partial failure", func() {
+			fake.FailOnce("/newsletter/lists/email/get.json", "internal error")
+			client.Concurrency = 1
+
+			memberships, err := client.ListsForEmail(email)
+			Expect(memberships).To(HaveLen(1))
+			Expect(memberships[0].List.Name).To(Equal("billing"))
+
+			var errs ListErrors
+			Expect(errors.As(err, &errs)).To(BeTrue())
+			Expect(errs).To(HaveLen(1))
+			Expect(errs).To(HaveKey("news"))
+			Expect(err).To(MatchError("sendbit: client.ListsForEmail error: The call failed " +
+				"for 1 list(s): news: sendbit: client.Recipient error: internal error"))
+		})
+	})
+
+	Context("when the email is not normalized", func() {
+		It("looks up the normalized email", func() {
+			memberships, err := client.ListsForEmail(" alice@EXAMPLE.com ")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(memberships).To(HaveLen(2))
+		})
+
+		It("fails to lookup a malformed email", func() {
+			_, err := client.ListsForEmail("alice")
+			Expect(IsInvalidEmail(err)).To(BeTrue())
+		})
+	})
+
+	Context("when the email is empty", func() {
+		It("fails to lookup", func() {
+			_, err := client.ListsForEmail("")
+			Expect(err).To(MatchError("sendbit: client.ListsForEmail error: The email is empty."))
+		})
+	})
+
+	Context("when the v3 API is used", func() {
+		var marketing *FakeMarketing
+
+		BeforeEach(func() {
+			marketing = NewFakeMarketing()
+			marketing.AddList("news", Recipient{Name: "Alice", Email: email})
+			marketing.AddList("offers", Recipient{Email: "bob@example.com"})
+			marketing.AddList("billing", Recipient{Name: "Alice", Email: email})
+			client = marketing.Client()
+		})
+
+		AfterEach(func() {
+			marketing.Close()
+		})
+
+		It("reads the lists from a single search", func() {
+			memberships, err := client.ListsForEmail(email)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(memberships).To(Equal([]Membership{
+				{List: List{UUID: marketing.Lists[0].ID, Name: "news"}, Recipient: Recipient{Name: "Alice", Email: email}},
+				{List: List{UUID: marketing.Lists[2].ID, Name: "billing"}, Recipient: Recipient{Name: "Alice", Email: email}},
+			}))
+			Expect(marketing.Requests).To(Equal([]string{
+				"POST /v3/marketing/contacts/search/emails",
+				"GET /v3/marketing/lists",
+			}))
+		})
+	})
+})
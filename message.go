@@ -0,0 +1,165 @@
+package sendbit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Represents an email address with an optional display name
+type Address struct {
+	// The email address
+	Email string `json:"email"`
+	// The display name
+	Name string `json:"name,omitempty"`
+}
+
+// Represents an email sent through the v3 Mail Send API. The subject and
+// the content come from the template when TemplateID is set.
+type Message struct {
+	// The sender
+	From Address
+	// The recipients
+	To []Address
+	// The subject line
+	Subject string
+	// The plain text content
+	Text string
+	// The HTML content
+	HTML string
+	// TemplateID - The dynamic template rendered for the message
+	TemplateID string
+	// TemplateData - The dynamic template data. Any value encoded as
+	// a JSON object, such as a struct or a map, is accepted.
+	TemplateData interface{}
+	// The categories the message is reported under
+	Categories []string
+	// UnsubscribeGroupID - The unsubscribe group the message belongs to.
+	// The recipients suppressed by the group are skipped and the
+	// unsubscribe links opt out of the group only.
+	UnsubscribeGroupID int64
+	// GroupsToDisplay - The unsubscribe groups shown on the preferences
+	// page linked from the message
+	GroupsToDisplay []int64
+}
+
+type messageASM struct {
+	GroupID         int64   `json:"group_id"`
+	GroupsToDisplay []int64 `json:"groups_to_display,omitempty"`
+}
+
+type messageContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type messagePersonalization struct {
+	To           []Address       `json:"to"`
+	TemplateData json.RawMessage `json:"dynamic_template_data,omitempty"`
+}
+
+type messagePayload struct {
+	Personalizations []messagePersonalization `json:"personalizations"`
+	From             Address                  `json:"from"`
+	Subject          string                   `json:"subject,omitempty"`
+	Content          []messageContent         `json:"content,omitempty"`
+	TemplateID       string                   `json:"template_id,omitempty"`
+	Categories       []string                 `json:"categories,omitempty"`
+	ASM              *messageASM              `json:"asm,omitempty"`
+}
+
+// Builds the Mail Send request body
+func (client *Client) messagePayload(message *Message) (*messagePayload, error) {
+	if message == nil {
+		return nil, errors.New("The message is nil.")
+	}
+
+	from, err := normalizeEmail(message.From.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(message.To) == 0 {
+		return nil, errors.New("The message has no recipients.")
+	}
+
+	personalization := messagePersonalization{To: make([]Address, len(message.To))}
+	for index, address := range message.To {
+		email, err := normalizeEmail(address.Email)
+		if err != nil {
+			return nil, err
+		}
+		personalization.To[index] = Address{Email: email, Name: address.Name}
+	}
+
+	payload := &messagePayload{
+		From:       Address{Email: from, Name: message.From.Name},
+		Subject:    message.Subject,
+		TemplateID: message.TemplateID,
+		Categories: message.Categories,
+	}
+
+	if message.TemplateData != nil {
+		if message.TemplateID == "" {
+			return nil, errors.New("The template data requires a template.")
+		}
+
+		data, err := json.Marshal(message.TemplateData)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) == 0 || data[0] != '{' {
+			return nil, fmt.Errorf("The template data of type %T is not a JSON object.", message.TemplateData)
+		}
+		personalization.TemplateData = data
+	}
+
+	if message.UnsubscribeGroupID != 0 {
+		payload.ASM = &messageASM{
+			GroupID:         message.UnsubscribeGroupID,
+			GroupsToDisplay: message.GroupsToDisplay,
+		}
+	} else if len(message.GroupsToDisplay) > 0 {
+		return nil, errors.New("The groups to display require an unsubscribe group.")
+	}
+
+	if message.Text != "" {
+		payload.Content = append(payload.Content, messageContent{Type: "text/plain", Value: message.Text})
+	}
+	if message.HTML != "" {
+		payload.Content = append(payload.Content, messageContent{Type: "text/html", Value: message.HTML})
+	}
+
+	if message.TemplateID == "" && (message.Subject == "" || len(payload.Content) == 0) {
+		return nil, errors.New("The message needs a subject and a content or a template.")
+	}
+
+	payload.Personalizations = []messagePersonalization{personalization}
+	return payload, nil
+}
+
+// Send an email through the v3 Mail Send API
+func (client *Client) Send(message *Message) error {
+	ctx, span := client.startSpan("Send")
+	defer span.End()
+
+	errorf := func(err error) error {
+		return client.errorf("Send", err)
+	}
+
+	if err := client.requireMarketing(); err != nil {
+		return errorf(err)
+	}
+
+	payload, err := client.messagePayload(message)
+	if err != nil {
+		return errorf(err)
+	}
+
+	if _, err := client.request(ctx, http.MethodPost, "/mail/send", payload); err != nil {
+		return errorf(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package sendbit_test
+
+import (
+	"encoding/json"
+
+	. "github.com/svett/sendbit"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Send", func() {
+	var (
+		fake    *FakeMarketing
+		client  *Client
+		message *Message
+	)
+
+	type welcome struct {
+		Name  string   `json:"name"`
+		Items []string `json:"items"`
+	}
+
+	BeforeEach(func() {
+		fake = NewFakeMarketing()
+		client = fake.Client()
+		message = &Message{
+			From:       Address{Email: "news@example.com", Name: "News"},
+			To:         []Address{{Email: "j.smith@EXAMPLE.com", Name: "John Smith"}},
+			Subject:    "Hello",
+			Text:       "Hi John",
+			HTML:       "<p>Hi John</p>",
+			Categories: []string{"welcome"},
+		}
+	})
+
+	AfterEach(func() {
+		fake.Close()
+	})
+
+	It("sends the content", func() {
+		Expect(client.Send(message)).To(Succeed())
+		Expect(fake.Mail).To(HaveLen(1))
+
+		body, err := json.Marshal(fake.Mail[0])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(body).To(MatchJSON(`{
+			"personalizations": [{"to": [{"email": "j.smith@example.com", "name": "John Smith"}]}],
+			"from": {"email": "news@example.com", "name": "News"},
+			"subject": "Hello",
+			"content": [
+				{"type": "text/plain", "value": "Hi John"},
+				{"type": "text/html", "value": "<p>Hi John</p>"}
+			],
+			"categories": ["welcome"]
+		}`))
+	})
+
+	It("sends a template with its data", func() {
+		message.Subject, message.Text, message.HTML = "", "", ""
+		message.TemplateID = "d-1"
+		message.TemplateData = welcome{Name: "John", Items: []string{"a", "b"}}
+
+		Expect(client.Send(message)).To(Succeed())
+		Expect(string(fake.Mail[0]["template_id"])).To(Equal(`"d-1"`))
+
+		var personalizations []map[string]json.RawMessage
+		Expect(json.Unmarshal(fake.Mail[0]["personalizations"], &personalizations)).To(Succeed())
+		Expect(personalizations[0]["dynamic_template_data"]).To(MatchJSON(`{"name": "John", "items": ["a", "b"]}`))
+	})
+
+	Context("when the template data is not an object", func() {
+		It("fails to send", func() {
+			message.TemplateID = "d-1"
+			message.TemplateData = []string{"a"}
+			err := client.Send(message)
+			Expect(err).To(MatchError("sendbit: client.Send error: " +
+				"The template data of type []string is not a JSON object."))
+			Expect(fake.Mail).To(BeEmpty())
+		})
+	})
+
+	Context("when the message has no content nor template", func() {
+		It("fails to send", func() {
+			message.Text, message.HTML = "", ""
+			err := client.Send(message)
+			Expect(err).To(MatchError("sendbit: client.Send error: " +
+				"The message needs a subject and a content or a template."))
+		})
+	})
+
+	Context("when a recipient is invalid", func() {
+		It("fails to send", func() {
+			message.To = append(message.To, Address{Email: "nobody"})
+			Expect(IsInvalidEmail(client.Send(message))).To(BeTrue())
+			Expect(fake.Mail).To(BeEmpty())
+		})
+	})
+
+	Context("when the client validator rejects role accounts", func() {
+		It("still sends from and to them", func() {
+			client.Validator = &EmailValidator{RejectRole: true}
+			message.From.Email = "support@example.com"
+			message.To = []Address{{Email: "admin@example.com"}}
+			Expect(client.Send(message)).To(Succeed())
+			Expect(fake.Mail).To(HaveLen(1))
+		})
+	})
+})
@@ -0,0 +1,107 @@
+package sendbit
+
+import (
+	"context"
+	"iter"
+)
+
+// The number of the items per page when Pager.PageSize is zero
+const DefaultPageSize = 100
+
+// Fetches the page that starts at a cursor and returns the cursor of the
+// next one. The cursor is empty for the first page and after the last one.
+type pageFunc[T any] func(ctx context.Context, cursor string, size int) ([]T, string, error)
+
+// Iterates over the items of a list-style call page by page, so large
+// accounts are fetched in bounded requests instead of a single one.
+// Every page is fetched in its own span. A pager is not safe for
+// concurrent use.
+type Pager[T any] struct {
+	// PageSize - The maximum number of the items per page. DefaultPageSize
+	// is used when it is zero. The endpoints may cap it further.
+	PageSize int
+
+	client *Client
+	method string
+	fetch  pageFunc[T]
+	cursor string
+	done   bool
+}
+
+func newPager[T any](client *Client, method string, fetch pageFunc[T]) *Pager[T] {
+	return &Pager[T]{client: client, method: method, fetch: fetch}
+}
+
+// Builds a pager over an API that does not page, so every item comes in
+// a single page regardless of the page size
+func singlePager[T any](client *Client, method string, fetch func(ctx context.Context) ([]T, error)) *Pager[T] {
+	return newPager(client, method, func(ctx context.Context, cursor string, size int) ([]T, string, error) {
+		items, err := fetch(ctx)
+		return items, "", err
+	})
+}
+
+// Determines whether every page has been fetched
+func (pager *Pager[T]) Done() bool {
+	return pager.done
+}
+
+// Fetches the next page. It returns an empty page when the pager is done.
+func (pager *Pager[T]) Next(ctx context.Context) ([]T, error) {
+	if pager.done {
+		return nil, nil
+	}
+
+	ctx, span := pager.client.WithContext(ctx).startSpan(pager.method)
+	defer span.End()
+
+	size := pager.PageSize
+	if size <= 0 {
+		size = DefaultPageSize
+	}
+
+	items, cursor, err := pager.fetch(ctx, pager.cursor, size)
+	if err != nil {
+		return nil, pager.client.errorf(pager.method, err)
+	}
+
+	// A repeated cursor would fetch the same page forever
+	pager.done = cursor == "" || cursor == pager.cursor
+	pager.cursor = cursor
+	return items, nil
+}
+
+// Fetches the remaining pages and returns their items
+func (pager *Pager[T]) All(ctx context.Context) ([]T, error) {
+	items := []T{}
+	for !pager.done {
+		page, err := pager.Next(ctx)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, page...)
+	}
+	return items, nil
+}
+
+// Iterates over the remaining items fetching the pages on demand. An
+// error is yielded once with the zero item and ends the iteration.
+// Stopping the iteration early drops the rest of the current page.
+func (pager *Pager[T]) Items(ctx context.Context) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for !pager.done {
+			page, err := pager.Next(ctx)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+
+			for _, item := range page {
+				if !yield(item, nil) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package sendbit_test
+
+import (
+	"context"
+
+	. "github.com/svett/sendbit"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Pager", func() {
+	var (
+		fake   *FakeMarketing
+		client *Client
+		ctx    context.Context
+	)
+
+	BeforeEach(func() {
+		fake = NewFakeMarketing()
+		client = fake.Client()
+		ctx = context.Background()
+
+		fake.AddList("newsletter",
+			Recipient{Email: "j.smith@example.com"},
+			Recipient{Email: "m.freeman@example.com"},
+			Recipient{Email: "j.jones@example.com"},
+		)
+		fake.AddList("offers")
+		fake.AddList("billing")
+	})
+
+	AfterEach(func() {
+		fake.Close()
+	})
+
+	It("fetches the lists page by page", func() {
+		pager := client.ListPager()
+		pager.PageSize = 2
+
+		page, err := pager.Next(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(page).To(HaveLen(2))
+		Expect(pager.Done()).To(BeFalse())
+
+		page, err = pager.Next(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(page).To(HaveLen(1))
+		Expect(page[0].Name).To(Equal("billing"))
+		Expect(pager.Done()).To(BeTrue())
+
+		page, err = pager.Next(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(page).To(BeEmpty())
+		Expect(fake.Requests).To(Equal([]string{
+			"GET /v3/marketing/lists",
+			"GET /v3/marketing/lists",
+		}))
+	})
+
+	It("fetches every recipient", func() {
+		pager := client.RecipientPager("newsletter")
+		pager.PageSize = 2
+
+		recipients, err := pager.All(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(recipients).To(HaveLen(3))
+		Expect(pager.Done()).To(BeTrue())
+	})
+
+	It("iterates over the items", func() {
+		pager := client.RecipientPager("newsletter")
+		pager.PageSize = 1
+
+		emails := []string{}
+		for recipient, err := range pager.Items(ctx) {
+			Expect(err).ToNot(HaveOccurred())
+			emails = append(emails, recipient.Email)
+			if len(emails) == 2 {
+				break
+			}
+		}
+		Expect(emails).To(HaveLen(2))
+		Expect(pager.Done()).To(BeFalse())
+	})
+
+	Context("when a page fails", func() {
+		It("yields the error once", func() {
+			count := 0
+			for _, err := range client.RecipientPager("unknown").Items(ctx) {
+				Expect(err).To(MatchError("sendbit: client.Recipients error: the title(s) 'unknown' do not exist"))
+				count++
+			}
+			Expect(count).To(Equal(1))
+		})
+	})
+
+	It("fetches the unpaged v3 endpoints in a single page", func() {
+		fake.AddField("plan", "Text")
+		group, err := client.CreateUnsubscribeGroup(&UnsubscribeGroup{Name: "Weekly"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(client.AddGroupSuppressions(group.ID, "a@example.com", "b@example.com")).To(Succeed())
+
+		suppressions := client.GroupSuppressionPager(group.ID)
+		suppressions.PageSize = 1
+		emails, err := suppressions.Next(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(emails).To(HaveLen(2))
+		Expect(suppressions.Done()).To(BeTrue())
+
+		definitions, err := client.FieldDefinitionPager().All(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(definitions).To(HaveLen(1))
+
+		groups, err := client.UnsubscribeGroupPager().All(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(groups).To(HaveLen(1))
+
+		segments, err := client.SegmentPager().All(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(segments).To(BeEmpty())
+	})
+
+	Context("when the client uses the legacy API", func() {
+		var legacy *FakeSendGrid
+
+		BeforeEach(func() {
+			legacy = NewFakeSendGrid()
+			legacy.Suppressions["bounces"] = []map[string]string{
+				{"email": "a@example.com", "status": "5.1.1"},
+				{"email": "b@example.com", "status": "5.1.1"},
+				{"email": "c@example.com", "status": "5.1.1"},
+				{"email": "d@example.com", "status": "5.1.1"},
+			}
+			client = legacy.Client()
+		})
+
+		AfterEach(func() {
+			legacy.Close()
+		})
+
+		It("pages the suppressions by offset", func() {
+			pager := client.BouncePager(nil)
+			pager.PageSize = 3
+
+			bounces, err := pager.All(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(bounces).To(HaveLen(4))
+			Expect(legacy.Forms).To(HaveLen(2))
+			Expect(legacy.Forms[1].Get("offset")).To(Equal("3"))
+		})
+
+		It("stops at the filter limit", func() {
+			pager := client.BouncePager(&SuppressionFilter{Offset: 1, Limit: 2})
+			pager.PageSize = 1
+
+			bounces, err := pager.All(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(bounces).To(HaveLen(2))
+			Expect(bounces[0].Email).To(Equal("b@example.com"))
+			Expect(bounces[1].Email).To(Equal("c@example.com"))
+		})
+
+		It("fetches the lists in a single page", func() {
+			legacy.AddList("newsletter")
+			pager := client.ListPager()
+			pager.PageSize = 1
+
+			lists, err := pager.All(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lists).To(HaveLen(1))
+			Expect(lists[0].Name).To(Equal("newsletter"))
+		})
+	})
+})
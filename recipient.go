@@ -1,18 +1,28 @@
 package sendbit
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
-	"net/url"
+	"fmt"
 	"strings"
 )
 
+// The maximum number of recipients sent by a single add request
+const MaxBatchSize = 1000
+
 // Determines whether a recipient already exist error
 func IsRecipientExist(err error) bool {
 	return strings.HasSuffix(err.Error(), "The recipient already exist.")
 }
 
-// Determines whether a recipient doest not exist error
+// Determines whether a recipient is globally unsubscribed error
+func IsRecipientUnsubscribed(err error) bool {
+	return strings.HasSuffix(err.Error(), "The recipient is unsubscribed.")
+}
+
+// Determines whether a recipient does not exist error
 func IsRecipientNotExist(err error) bool {
 	return strings.HasSuffix(err.Error(), "The recipient does not exist.")
 }
@@ -23,10 +33,78 @@ type Recipient struct {
 	Name string `json:"name"`
 	// This is a recipient's email
 	Email string `json:"email"`
+	// These are the recipient's custom fields
+	Fields map[string]string `json:"-"`
+}
+
+// Encodes the recipient with its custom fields flattened next to
+// the name and the email.
+func (recipient Recipient) MarshalJSON() ([]byte, error) {
+	type plain Recipient
+	body, err := json.Marshal(plain(recipient))
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]string, len(recipient.Fields))
+	for name, value := range recipient.Fields {
+		if name != "name" && name != "email" {
+			fields[name] = value
+		}
+	}
+	if len(fields) == 0 {
+		return body, nil
+	}
+
+	custom, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	body = append(body[:len(body)-1], ',')
+	return append(body, custom[1:]...), nil
+}
+
+// Decodes a recipient whose custom fields are flattened next to
+// the name and the email.
+func (recipient *Recipient) UnmarshalJSON(body []byte) error {
+	// The numbers are kept as written instead of being formatted as floats
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var fields map[string]interface{}
+	if err := decoder.Decode(&fields); err != nil {
+		return err
+	}
+
+	*recipient = Recipient{}
+	for name, value := range fields {
+		text, ok := value.(string)
+		if !ok && value != nil {
+			text = fmt.Sprint(value)
+		}
+
+		switch name {
+		case "name":
+			recipient.Name = text
+		case "email":
+			recipient.Email = text
+		default:
+			if recipient.Fields == nil {
+				recipient.Fields = map[string]string{}
+			}
+			recipient.Fields[name] = text
+		}
+	}
+
+	return nil
 }
 
 // Add an email recipient to a list
 func (client *Client) AddRecipient(list string, recipient *Recipient) error {
+	ctx, span := client.startSpan("AddRecipient")
+	defer span.End()
+
 	errorf := func(err error) error {
 		return client.errorf("AddRecipient", err)
 	}
@@ -35,35 +113,203 @@ func (client *Client) AddRecipient(list string, recipient *Recipient) error {
 		return errorf(errors.New("The list is empty."))
 	}
 
-	if recipient == nil || recipient.Email == "" {
-		return errorf(errors.New("The recipeint is nil or has invalid email."))
+	recipient, err := client.normalizeRecipient(recipient)
+	if err != nil {
+		return errorf(err)
+	}
+
+	defer client.invalidate(cacheKey("RecipientCount", list))
+	if client.SkipUnsubscribed {
+		unsubscribed, err := client.unsubscribed(ctx, recipient.Email)
+		if err != nil {
+			return errorf(err)
+		}
+
+		if unsubscribed[strings.ToLower(recipient.Email)] {
+			return errorf(errors.New("The recipient is unsubscribed."))
+		}
+	}
+
+	backend := client.backend()
+	if err := backend.checkRecipients(ctx, []Recipient{*recipient}); err != nil {
+		return errorf(err)
 	}
 
-	body, err := json.Marshal(&recipient)
+	inserted, _, err := backend.addRecipients(ctx, list, []Recipient{*recipient})
 	if err != nil {
 		return errorf(err)
 	}
 
-	data := url.Values{}
-	data.Add("list", list)
-	data.Add("data", string(body))
+	if inserted == 0 {
+		return errorf(errors.New("The recipient already exist."))
+	}
+
+	return nil
+}
+
+// Summarizes the addition of many recipients
+type AddResult struct {
+	// The number of the inserted recipients
+	Inserted int
+	// The number of the recipients that already exist in the list
+	Existing int
+	// The skipped emails which are globally unsubscribed
+	Unsubscribed []string
+	// The jobs ingesting the recipients when the v3 API is used
+	Jobs []*Job
+}
+
+// Add many email recipients to a list. The recipients are sent in batches
+// of MaxBatchSize. The globally unsubscribed recipients are skipped when
+// Client.SkipUnsubscribed is set and returned in the result. The v3 API
+// ingests the recipients asynchronously, so the returned jobs have to be
+// waited on to know whether they were actually added.
+func (client *Client) AddRecipients(list string, recipients []Recipient) (*AddResult, error) {
+	ctx, span := client.startSpan("AddRecipients")
+	defer span.End()
+
+	errorf := func(err error) error {
+		return client.errorf("AddRecipients", err)
+	}
+
+	result := &AddResult{}
+	if list == "" {
+		return result, errorf(errors.New("The list is empty."))
+	}
+
+	normalized := make([]Recipient, len(recipients))
+	for index := range recipients {
+		recipient, err := client.normalizeRecipient(&recipients[index])
+		if err != nil {
+			return result, errorf(err)
+		}
+		normalized[index] = *recipient
+	}
+
+	unsubscribed := map[string]bool{}
+	if client.SkipUnsubscribed && len(normalized) > 0 {
+		var err error
+		if unsubscribed, err = client.unsubscribed(ctx, emails(normalized)...); err != nil {
+			return result, errorf(err)
+		}
+	}
+
+	batch := make([]Recipient, 0, len(normalized))
+	for _, recipient := range normalized {
+		if unsubscribed[strings.ToLower(recipient.Email)] {
+			result.Unsubscribed = append(result.Unsubscribed, recipient.Email)
+			continue
+		}
+		batch = append(batch, recipient)
+	}
+
+	backend := client.backend()
+	if err := backend.checkRecipients(ctx, batch); err != nil {
+		return result, errorf(err)
+	}
+
+	defer client.invalidate(cacheKey("RecipientCount", list))
+	for start := 0; start < len(batch); start += MaxBatchSize {
+		end := start + MaxBatchSize
+		if end > len(batch) {
+			end = len(batch)
+		}
+
+		inserted, job, err := backend.addRecipients(ctx, list, batch[start:end])
+		if err != nil {
+			return result, errorf(err)
+		}
+		if job != nil {
+			result.Jobs = append(result.Jobs, job)
+		}
+		result.Inserted += inserted
+		result.Existing += end - start - inserted
+	}
+
+	return result, nil
+}
+
+// Change the name and the custom fields of a recipient in a list. The v3
+// API updates the contact in place and waits for its job. The v2 API
+// removes the recipient and adds it back, restoring the previous recipient
+// when it cannot be added back.
+func (client *Client) UpdateRecipient(list string, recipient *Recipient) error {
+	return client.saveRecipient("UpdateRecipient", list, recipient, false)
+}
+
+// Add an email recipient to a list or update its name and custom fields
+// when it already exists. The update is performed as UpdateRecipient does.
+func (client *Client) UpsertRecipient(list string, recipient *Recipient) error {
+	return client.saveRecipient("UpsertRecipient", list, recipient, true)
+}
+
+// Updates an existing recipient or adds a missing one when upsert is set
+func (client *Client) saveRecipient(method, list string, recipient *Recipient, upsert bool) error {
+	ctx, span := client.startSpan(method)
+	defer span.End()
+
+	errorf := func(err error) error {
+		return client.errorf(method, err)
+	}
 
-	response, err := client.post("/newsletter/lists/email/add.json", data)
+	if list == "" {
+		return errorf(errors.New("The list is empty."))
+	}
 
+	recipient, err := client.normalizeRecipient(recipient)
 	if err != nil {
 		return errorf(err)
 	}
 
-	var stats struct {
-		AffectedRows int `json:"inserted"`
+	backend := client.backend()
+	existing, err := backend.recipients(ctx, list, recipient.Email)
+	if err != nil {
+		return errorf(err)
 	}
 
-	if err := json.NewDecoder(response).Decode(&stats); err != nil {
-		return errorf(err)
+	var job *Job
+	if len(existing) == 0 {
+		if !upsert {
+			return errorf(errors.New("The recipient does not exist."))
+		}
+
+		if client.SkipUnsubscribed {
+			unsubscribed, err := client.unsubscribed(ctx, recipient.Email)
+			if err != nil {
+				return errorf(err)
+			}
+
+			if unsubscribed[strings.ToLower(recipient.Email)] {
+				return errorf(errors.New("The recipient is unsubscribed."))
+			}
+		}
+
+		if err := backend.checkRecipients(ctx, []Recipient{*recipient}); err != nil {
+			return errorf(err)
+		}
+
+		defer client.invalidate(cacheKey("RecipientCount", list))
+		if _, job, err = backend.addRecipients(ctx, list, []Recipient{*recipient}); err != nil {
+			return errorf(err)
+		}
+	} else {
+		if sameRecipient(&existing[0], recipient) {
+			return nil
+		}
+
+		if err := backend.checkRecipients(ctx, []Recipient{*recipient}); err != nil {
+			return errorf(err)
+		}
+
+		if job, err = backend.updateRecipient(ctx, list, &existing[0], recipient); err != nil {
+			return errorf(err)
+		}
 	}
 
-	if stats.AffectedRows == 0 {
-		return errorf(errors.New("The recipient already exist."))
+	if job != nil {
+		if err := job.Wait(ctx); err != nil {
+			return errorf(err)
+		}
 	}
 
 	return nil
@@ -71,6 +317,9 @@ func (client *Client) AddRecipient(list string, recipient *Recipient) error {
 
 // Remove one or more emails from a Recipient List.
 func (client *Client) DeleteRecipient(list, email string) error {
+	ctx, span := client.startSpan("DeleteRecipient")
+	defer span.End()
+
 	errorf := func(err error) error {
 		return client.errorf("DeleteRecipient", err)
 	}
@@ -83,32 +332,56 @@ func (client *Client) DeleteRecipient(list, email string) error {
 		return errorf(errors.New("The recipeint email is empty."))
 	}
 
-	data := url.Values{}
-	data.Add("list", list)
-	data.Add("email[]", email)
-
-	response, err := client.post("/newsletter/lists/email/delete.json", data)
+	defer client.invalidate(cacheKey("RecipientCount", list))
+	removed, err := client.backend().deleteRecipients(ctx, list, []string{email})
 	if err != nil {
 		return errorf(err)
 	}
 
-	var stats struct {
-		AffectedRows int `json:"removed"`
+	if removed == 0 {
+		return errorf(errors.New("The recipient does not exist."))
 	}
 
-	if err := json.NewDecoder(response).Decode(&stats); err != nil {
-		return errorf(err)
+	return nil
+}
+
+// Remove many emails from a Recipient List. The emails are sent in batches
+// of MaxBatchSize and the number of the removed ones is returned.
+func (client *Client) DeleteRecipients(list string, emails []string) (int, error) {
+	ctx, span := client.startSpan("DeleteRecipients")
+	defer span.End()
+
+	errorf := func(err error) error {
+		return client.errorf("DeleteRecipients", err)
 	}
 
-	if stats.AffectedRows == 0 {
-		return errorf(errors.New("The recipient doest not exist."))
+	if list == "" {
+		return 0, errorf(errors.New("The list is empty."))
 	}
 
-	return nil
+	defer client.invalidate(cacheKey("RecipientCount", list))
+	removed := 0
+	for start := 0; start < len(emails); start += MaxBatchSize {
+		end := start + MaxBatchSize
+		if end > len(emails) {
+			end = len(emails)
+		}
+
+		count, err := client.backend().deleteRecipients(ctx, list, emails[start:end])
+		removed += count
+		if err != nil {
+			return removed, errorf(err)
+		}
+	}
+
+	return removed, nil
 }
 
 // Get the email and associated fields for a Recipient List.
 func (client *Client) Recipient(list, email string) (*Recipient, error) {
+	ctx, span := client.startSpan("Recipient")
+	defer span.End()
+
 	errorf := func(err error) error {
 		return client.errorf("Recipient", err)
 	}
@@ -121,16 +394,7 @@ func (client *Client) Recipient(list, email string) (*Recipient, error) {
 		return nil, errorf(errors.New("The email is empty."))
 	}
 
-	data := url.Values{}
-	data.Add("list", list)
-	data.Add("email", email)
-	response, err := client.post("/newsletter/lists/email/get.json", data)
-	if err != nil {
-		return nil, errorf(err)
-	}
-
-	var recipients []Recipient
-	err = json.NewDecoder(response).Decode(&recipients)
+	recipients, err := client.backend().recipients(ctx, list, email)
 	if err != nil {
 		return nil, errorf(err)
 	}
@@ -144,32 +408,24 @@ func (client *Client) Recipient(list, email string) (*Recipient, error) {
 
 // Get the email addresses and associated fields for a Recipient List.
 func (client *Client) Recipients(list string) ([]Recipient, error) {
-	errorf := func(err error) error {
-		return client.errorf("Recipients", err)
-	}
-
-	if list == "" {
-		return nil, errorf(errors.New("The list is empty."))
-	}
-
-	data := url.Values{}
-	data.Add("list", list)
-	response, err := client.post("/newsletter/lists/email/get.json", data)
-	if err != nil {
-		return nil, errorf(err)
-	}
-
-	var recipients []Recipient
-	err = json.NewDecoder(response).Decode(&recipients)
-	if err != nil {
-		return nil, errorf(err)
-	}
+	return client.RecipientPager(list).All(client.context())
+}
 
-	return recipients, nil
+// Page through the recipients of a list
+func (client *Client) RecipientPager(list string) *Pager[Recipient] {
+	return newPager(client, "Recipients", func(ctx context.Context, cursor string, size int) ([]Recipient, string, error) {
+		if list == "" {
+			return nil, "", errors.New("The list is empty.")
+		}
+		return client.backend().recipientPage(ctx, list, cursor, size)
+	})
 }
 
 // Retrieve the number of entries on a list.
 func (client *Client) RecipientCount(list string) (uint64, error) {
+	ctx, span := client.startSpan("RecipientCount")
+	defer span.End()
+
 	errorf := func(err error) error {
 		return client.errorf("RecipientCount", err)
 	}
@@ -178,21 +434,12 @@ func (client *Client) RecipientCount(list string) (uint64, error) {
 		return 0, errorf(errors.New("The list is empty."))
 	}
 
-	data := url.Values{}
-	data.Add("list", list)
-	response, err := client.post("/newsletter/lists/email/count.json", data)
-	if err != nil {
-		return 0, errorf(err)
-	}
-
-	var stats struct {
-		Count uint64 `json:"count"`
-	}
-
-	err = json.NewDecoder(response).Decode(&stats)
+	count, err := client.cached(ctx, cacheKey("RecipientCount", list), func(ctx context.Context) (interface{}, error) {
+		return client.backend().recipientCount(ctx, list)
+	})
 	if err != nil {
 		return 0, errorf(err)
 	}
 
-	return stats.Count, nil
+	return count.(uint64), nil
 }
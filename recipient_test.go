@@ -1,6 +1,7 @@
 package sendbit_test
 
 import (
+	"encoding/json"
 	"errors"
 
 	. "github.com/svett/sendbit"
@@ -156,7 +157,7 @@ var _ = Describe("Recipient", func() {
 		It("fails to delete it", func() {
 			Expect(client.DeleteRecipient(list, "no.exists@example.com")).To(
 				MatchError("sendbit: client.DeleteRecipient error: " +
-					"The recipient doest not exist."))
+					"The recipient does not exist."))
 		})
 	})
 
@@ -202,3 +203,129 @@ var _ = Describe("Recipient", func() {
 		})
 	})
 })
+
+var _ = Describe("Recipient decoding", func() {
+	It("keeps the numbers as written", func() {
+		var recipient Recipient
+		body := []byte(`{"email":"j.smith@example.com","score":1234567,"ratio":0.25}`)
+		Expect(json.Unmarshal(body, &recipient)).To(Succeed())
+		Expect(recipient.Fields).To(Equal(map[string]string{"score": "1234567", "ratio": "0.25"}))
+	})
+})
+
+var _ = Describe("Recipient update", func() {
+	var (
+		fake   *FakeSendGrid
+		client *Client
+	)
+	const list = "newsletter"
+
+	BeforeEach(func() {
+		fake = NewFakeSendGrid()
+		fake.AddList(list, Recipient{Name: "John Smith", Email: "j.smith@example.com"})
+		client = fake.Client()
+	})
+
+	AfterEach(func() {
+		fake.Close()
+	})
+
+	It("changes the recipient fields", func() {
+		recipient := &Recipient{
+			Name:   "Johnny Smith",
+			Email:  "j.smith@example.com",
+			Fields: map[string]string{"plan": "pro"},
+		}
+		Expect(client.UpdateRecipient(list, recipient)).To(Succeed())
+		Expect(fake.Recipients(list)).To(Equal([]Recipient{*recipient}))
+	})
+
+	Context("when the recipient does not exist", func() {
+		It("fails to update it", func() {
+			err := client.UpdateRecipient(list, &Recipient{Email: "m.j@example.com"})
+			Expect(IsRecipientNotExist(err)).To(BeTrue())
+		})
+	})
+
+	Context("when the recipient is unchanged", func() {
+		It("does not replace it", func() {
+			Expect(client.UpdateRecipient(list, &Recipient{
+				Name:  "John Smith",
+				Email: "j.smith@example.com",
+			})).To(Succeed())
+			Expect(fake.Requests).To(Equal([]string{"/newsletter/lists/email/get.json"}))
+		})
+	})
+
+	Context("when the recipient cannot be added back", func() {
+		It("restores the previous recipient", func() {
+			fake.FailOnce("/newsletter/lists/email/add.json", "internal error")
+			err := client.UpdateRecipient(list, &Recipient{Name: "Johnny", Email: "j.smith@example.com"})
+			Expect(err).To(MatchError("sendbit: client.UpdateRecipient error: internal error"))
+			Expect(fake.Recipients(list)).To(Equal([]Recipient{{Name: "John Smith", Email: "j.smith@example.com"}}))
+		})
+
+		Context("when the previous recipient cannot be restored", func() {
+			It("reports both errors", func() {
+				fake.Errors["/newsletter/lists/email/add.json"] = "internal error"
+				err := client.UpdateRecipient(list, &Recipient{Name: "Johnny", Email: "j.smith@example.com"})
+				Expect(err).To(MatchError("sendbit: client.UpdateRecipient error: " +
+					"internal error The rollback failed: internal error"))
+			})
+		})
+	})
+
+	Describe("UpsertRecipient", func() {
+		It("adds a new recipient", func() {
+			Expect(client.UpsertRecipient(list, &Recipient{Name: "M J", Email: "m.j@example.com"})).To(Succeed())
+			Expect(fake.Recipients(list)).To(HaveLen(2))
+		})
+
+		It("updates an existing recipient", func() {
+			Expect(client.UpsertRecipient(list, &Recipient{Name: "J S", Email: "j.smith@example.com"})).To(Succeed())
+			Expect(fake.Recipients(list)).To(Equal([]Recipient{{Name: "J S", Email: "j.smith@example.com"}}))
+		})
+	})
+
+	Context("when the v3 API is used", func() {
+		var marketing *FakeMarketing
+
+		BeforeEach(func() {
+			marketing = NewFakeMarketing()
+			marketing.AddList(list, Recipient{Name: "John Smith", Email: "j.smith@example.com"})
+			client = marketing.Client()
+		})
+
+		AfterEach(func() {
+			marketing.Close()
+		})
+
+		It("updates the contact in place and waits for its job", func() {
+			Expect(client.UpdateRecipient(list, &Recipient{Name: "Johnny Smith", Email: "j.smith@example.com"})).To(Succeed())
+			contact := marketing.Contacts["j.smith@example.com"]
+			Expect(contact.FirstName).To(Equal("Johnny"))
+			Expect(contact.ListIDs).To(HaveLen(1))
+			Expect(marketing.Requests).ToNot(ContainElement(HavePrefix("DELETE")))
+			Expect(marketing.Requests).To(ContainElement(HavePrefix("GET /v3/marketing/contacts/imports/")))
+		})
+
+		It("clears the removed name and custom fields", func() {
+			marketing.AddField("plan", "Text")
+			Expect(client.UpdateRecipient(list, &Recipient{
+				Name: "John Smith", Email: "j.smith@example.com", Fields: map[string]string{"plan": "pro"},
+			})).To(Succeed())
+
+			Expect(client.UpdateRecipient(list, &Recipient{Name: "John", Email: "j.smith@example.com"})).To(Succeed())
+			contact := marketing.Contacts["j.smith@example.com"]
+			Expect(contact.FirstName).To(Equal("John"))
+			Expect(contact.LastName).To(BeEmpty())
+			Expect(contact.CustomFields).To(BeEmpty())
+		})
+
+		It("adds a new contact and waits for its job", func() {
+			Expect(client.UpsertRecipient(list, &Recipient{Name: "M J", Email: "m.j@example.com"})).To(Succeed())
+			Expect(marketing.Members(marketing.Lists[0].ID)).To(HaveLen(2))
+			Expect(marketing.Requests).To(ContainElement(HavePrefix("GET /v3/marketing/contacts/imports/")))
+		})
+	})
+})
@@ -0,0 +1,147 @@
+package sendbit
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// The URL the unsubscribe placeholders are replaced with when
+// Renderer.UnsubscribeURL is empty
+const DefaultUnsubscribeURL = "#unsubscribe"
+
+// The placeholders that SendGrid replaces with the unsubscribe links
+var UnsubscribePlaceholders = []string{
+	"[Unsubscribe]",
+	"[unsubscribe]",
+	"[Unsubscribe_Preferences]",
+	"<%asm_group_unsubscribe_raw_url%>",
+	"<%asm_global_unsubscribe_raw_url%>",
+	"<%asm_preferences_raw_url%>",
+}
+
+var (
+	replacementTag  = regexp.MustCompile(`\[%\s*([^%|\]]+?)\s*(?:\|\s*([^%\]]*?)\s*)?%\]`)
+	substitutionTag = regexp.MustCompile(`-(\w+)-`)
+)
+
+// Renders a newsletter locally the way SendGrid personalizes it for
+// every recipient, so the previews can be reviewed without sending.
+//
+// The replacement tags such as [%name%], [%email%] or [%plan | free%]
+// are replaced with the recipient fields or their default value. The
+// -tag- substitutions are replaced with the Substitutions values. The
+// unsubscribe placeholders are replaced with UnsubscribeURL.
+type Renderer struct {
+	// Substitutions - The values of the -tag- substitutions keyed by
+	// the tag name without the dashes
+	Substitutions map[string]string
+	// UnsubscribeURL - The URL the unsubscribe placeholders are replaced
+	// with. DefaultUnsubscribeURL is used when it is empty.
+	UnsubscribeURL string
+}
+
+// Represents a newsletter rendered for a particular recipient
+type Preview struct {
+	// The recipient email
+	Email string
+	// The rendered body
+	Body string
+	// The sorted tags left in the body because they have no value
+	Unresolved []string
+}
+
+// Renders the body for a recipient
+func (renderer *Renderer) Render(body string, recipient *Recipient) *Preview {
+	if recipient == nil {
+		recipient = &Recipient{}
+	}
+
+	unresolved := map[string]bool{}
+	body = replacementTag.ReplaceAllStringFunc(body, func(tag string) string {
+		match := replacementTag.FindStringSubmatch(tag)
+		if value, ok := recipientField(recipient, match[1]); ok {
+			return value
+		}
+		if strings.Contains(tag, "|") {
+			return match[2]
+		}
+		unresolved[tag] = true
+		return tag
+	})
+
+	body = renderer.substitute(body, unresolved)
+
+	url := renderer.UnsubscribeURL
+	if url == "" {
+		url = DefaultUnsubscribeURL
+	}
+	for _, placeholder := range UnsubscribePlaceholders {
+		body = strings.ReplaceAll(body, placeholder, url)
+	}
+
+	preview := &Preview{Email: recipient.Email, Body: body}
+	for tag := range unresolved {
+		preview.Unresolved = append(preview.Unresolved, tag)
+	}
+	sort.Strings(preview.Unresolved)
+	return preview
+}
+
+// Renders the body for every recipient
+func (renderer *Renderer) RenderAll(body string, recipients []Recipient) []Preview {
+	previews := make([]Preview, len(recipients))
+	for index := range recipients {
+		previews[index] = *renderer.Render(body, &recipients[index])
+	}
+	return previews
+}
+
+// Replaces the -tag- substitutions. A tag is recognized only when it is
+// not a part of a hyphenated word, such as "well-known-fact".
+func (renderer *Renderer) substitute(body string, unresolved map[string]bool) string {
+	output := &strings.Builder{}
+	last := 0
+	for _, match := range substitutionTag.FindAllStringSubmatchIndex(body, -1) {
+		start, end := match[0], match[1]
+		if start < last || partOfWord(body, start-1) || partOfWord(body, end) {
+			continue
+		}
+
+		tag := body[start:end]
+		value, ok := renderer.Substitutions[body[match[2]:match[3]]]
+		if !ok {
+			unresolved[tag] = true
+			continue
+		}
+
+		output.WriteString(body[last:start])
+		output.WriteString(value)
+		last = end
+	}
+	output.WriteString(body[last:])
+	return output.String()
+}
+
+func partOfWord(body string, index int) bool {
+	if index < 0 || index >= len(body) {
+		return false
+	}
+	char := body[index]
+	return char == '-' || char == '_' ||
+		char >= '0' && char <= '9' ||
+		char >= 'a' && char <= 'z' ||
+		char >= 'A' && char <= 'Z'
+}
+
+func recipientField(recipient *Recipient, name string) (string, bool) {
+	switch name {
+	case "name":
+		return recipient.Name, recipient.Name != ""
+	case "email":
+		return recipient.Email, recipient.Email != ""
+	default:
+		value, ok := recipient.Fields[name]
+		return value, ok
+	}
+}
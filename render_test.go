@@ -0,0 +1,67 @@
+package sendbit_test
+
+import (
+	. "github.com/svett/sendbit"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Renderer", func() {
+	var (
+		renderer  *Renderer
+		recipient *Recipient
+	)
+
+	BeforeEach(func() {
+		renderer = &Renderer{
+			Substitutions:  map[string]string{"city": "Sofia", "offer": "20% off"},
+			UnsubscribeURL: "https://example.com/u",
+		}
+		recipient = &Recipient{
+			Name:   "John Smith",
+			Email:  "j.smith@example.com",
+			Fields: map[string]string{"plan": "pro"},
+		}
+	})
+
+	It("applies the replacement tags", func() {
+		preview := renderer.Render("Hi [%name%] <[%email%]>, your plan is [% plan %].", recipient)
+		Expect(preview.Body).To(Equal("Hi John Smith <j.smith@example.com>, your plan is pro."))
+		Expect(preview.Email).To(Equal("j.smith@example.com"))
+		Expect(preview.Unresolved).To(BeEmpty())
+	})
+
+	It("uses the default values", func() {
+		preview := renderer.Render("Hi [%first_name | there%], you are on [%plan|free%].", recipient)
+		Expect(preview.Body).To(Equal("Hi there, you are on pro."))
+	})
+
+	It("applies the substitutions", func() {
+		preview := renderer.Render("Events in -city-: -offer- for a well-known-fact.", recipient)
+		Expect(preview.Body).To(Equal("Events in Sofia: 20% off for a well-known-fact."))
+		Expect(preview.Unresolved).To(BeEmpty())
+	})
+
+	It("replaces the unsubscribe placeholders", func() {
+		preview := renderer.Render(`[Unsubscribe] or <a href="<%asm_group_unsubscribe_raw_url%>">opt out</a>`, recipient)
+		Expect(preview.Body).To(Equal(`https://example.com/u or <a href="https://example.com/u">opt out</a>`))
+	})
+
+	It("reports the unresolved tags", func() {
+		preview := renderer.Render("[%company%] in -country- and [%company%]", recipient)
+		Expect(preview.Body).To(Equal("[%company%] in -country- and [%company%]"))
+		Expect(preview.Unresolved).To(Equal([]string{"-country-", "[%company%]"}))
+	})
+
+	It("renders a preview per recipient", func() {
+		previews := renderer.RenderAll("Hi [%name | friend%]", []Recipient{
+			*recipient,
+			{Email: "m.freeman@example.com"},
+		})
+		Expect(previews).To(Equal([]Preview{
+			{Email: "j.smith@example.com", Body: "Hi John Smith"},
+			{Email: "m.freeman@example.com", Body: "Hi friend"},
+		}))
+	})
+})
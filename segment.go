@@ -0,0 +1,467 @@
+package sendbit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// The reserved contact fields that a segment query may refer to
+var ReservedFields = map[string]bool{
+	"contact_id":            true,
+	"email":                 true,
+	"first_name":            true,
+	"last_name":             true,
+	"alternate_emails":      true,
+	"address_line_1":        true,
+	"address_line_2":        true,
+	"city":                  true,
+	"state_province_region": true,
+	"postal_code":           true,
+	"country":               true,
+	"phone_number":          true,
+	"list_ids":              true,
+	"created_at":            true,
+	"updated_at":            true,
+}
+
+var fieldName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Represents a condition of a segment query. The conditions are built
+// from the fields returned by Field and CustomField and are combined with
+// All, Any and Negate. The first invalid field or value makes the whole
+// condition invalid. The custom fields are checked against the field
+// definitions when the segment is created or updated.
+type Condition struct {
+	sql    string
+	err    error
+	custom []customComparison
+}
+
+// A comparison of a custom field kept for checking it against the field
+// definitions
+type customComparison struct {
+	field    string
+	operator string
+}
+
+// The operators supported by every custom field type
+var fieldOperators = map[FieldType]map[string]bool{
+	TextField:   {"=": true, "!=": true, "LIKE": true, "IS NULL": true},
+	NumberField: {"=": true, "!=": true, ">": true, ">=": true, "<": true, "<=": true, "IS NULL": true},
+	DateField:   {"=": true, "!=": true, ">": true, ">=": true, "<": true, "<=": true, "IS NULL": true},
+}
+
+// Returns the condition in the segment query language
+func (condition Condition) String() string {
+	return condition.sql
+}
+
+// Refers to a contact field in a segment query
+type SegmentField struct {
+	name   string
+	err    error
+	custom bool
+}
+
+// Refers to a reserved contact field. The name is validated against
+// ReservedFields.
+func Field(name string) SegmentField {
+	if !ReservedFields[name] {
+		return SegmentField{name: name, err: fmt.Errorf("The field '%s' is not a reserved field.", name)}
+	}
+	return SegmentField{name: name}
+}
+
+// Refers to a custom contact field. The name and the operators applied
+// to it are checked against FieldDefinitions by CreateSegment and
+// UpdateSegment.
+func CustomField(name string) SegmentField {
+	if !fieldName.MatchString(name) {
+		return SegmentField{name: name, err: fmt.Errorf("The field name '%s' is invalid.", name)}
+	}
+	return SegmentField{name: name, custom: true}
+}
+
+// Matches the contacts whose field is equal to value
+func (field SegmentField) Eq(value interface{}) Condition {
+	return field.compare("=", value)
+}
+
+// Matches the contacts whose field is not equal to value
+func (field SegmentField) Ne(value interface{}) Condition {
+	return field.compare("!=", value)
+}
+
+// Matches the contacts whose field is greater than value
+func (field SegmentField) Gt(value interface{}) Condition {
+	return field.compare(">", value)
+}
+
+// Matches the contacts whose field is greater than or equal to value
+func (field SegmentField) Ge(value interface{}) Condition {
+	return field.compare(">=", value)
+}
+
+// Matches the contacts whose field is less than value
+func (field SegmentField) Lt(value interface{}) Condition {
+	return field.compare("<", value)
+}
+
+// Matches the contacts whose field is less than or equal to value
+func (field SegmentField) Le(value interface{}) Condition {
+	return field.compare("<=", value)
+}
+
+// Matches the contacts whose field matches a LIKE pattern
+func (field SegmentField) Like(pattern string) Condition {
+	return field.compare("LIKE", pattern)
+}
+
+// Matches the contacts whose field is not set
+func (field SegmentField) IsNull() Condition {
+	if field.err != nil {
+		return Condition{err: field.err}
+	}
+	return Condition{sql: field.name + " IS NULL", custom: field.comparison("IS NULL")}
+}
+
+func (field SegmentField) compare(operator string, value interface{}) Condition {
+	if field.err != nil {
+		return Condition{err: field.err}
+	}
+
+	literal, err := segmentLiteral(value)
+	if err != nil {
+		return Condition{err: fmt.Errorf("The value of the field '%s' is invalid: %w", field.name, err)}
+	}
+	return Condition{
+		sql:    fmt.Sprintf("%s %s %s", field.name, operator, literal),
+		custom: field.comparison(operator),
+	}
+}
+
+func (field SegmentField) comparison(operator string) []customComparison {
+	if !field.custom {
+		return nil
+	}
+	return []customComparison{{field: field.name, operator: operator}}
+}
+
+// Matches the members of a list with a particular v3 identificator
+func InList(id string) Condition {
+	if id == "" {
+		return Condition{err: errors.New("The list id is empty.")}
+	}
+	return Condition{sql: fmt.Sprintf("CONTAINS(list_ids, %s)", quoteSegment(id))}
+}
+
+// Matches the contacts that opened an email in the last days
+func OpenedWithin(days int) Condition {
+	if days <= 0 {
+		return Condition{err: fmt.Errorf("The number of days %d is not positive.", days)}
+	}
+	return Condition{sql: fmt.Sprintf("contact_id IN (SELECT contact_id FROM email_activity "+
+		"WHERE event_type = 'opened' AND event_timestamp >= "+
+		"TIMESTAMP_SUB(CURRENT_TIMESTAMP(), INTERVAL %d DAY))", days)}
+}
+
+// Matches the contacts that match every condition
+func All(conditions ...Condition) Condition {
+	return join("AND", conditions)
+}
+
+// Matches the contacts that match any condition
+func Any(conditions ...Condition) Condition {
+	return join("OR", conditions)
+}
+
+// Matches the contacts that do not match the condition
+func Negate(condition Condition) Condition {
+	if condition.err != nil {
+		return condition
+	}
+	if condition.sql == "" {
+		return Condition{err: errors.New("The segment condition is empty.")}
+	}
+	return Condition{sql: "NOT (" + condition.sql + ")", custom: condition.custom}
+}
+
+func join(operator string, conditions []Condition) Condition {
+	if len(conditions) == 0 {
+		return Condition{err: errors.New("The segment condition is empty.")}
+	}
+
+	parts := make([]string, len(conditions))
+	var custom []customComparison
+	for index, condition := range conditions {
+		if condition.err != nil {
+			return condition
+		}
+		if condition.sql == "" {
+			return Condition{err: errors.New("The segment condition is empty.")}
+		}
+		parts[index] = condition.sql
+		custom = append(custom, condition.custom...)
+	}
+
+	if len(parts) == 1 {
+		return Condition{sql: parts[0], custom: custom}
+	}
+	return Condition{sql: "(" + strings.Join(parts, " "+operator+" ") + ")", custom: custom}
+}
+
+// Compiles a condition to a segment query
+func SegmentQuery(condition Condition) (string, error) {
+	if condition.err != nil {
+		return "", condition.err
+	}
+	if condition.sql == "" {
+		return "", errors.New("The segment condition is empty.")
+	}
+	return "SELECT contact_id, updated_at FROM contact_data WHERE " + condition.sql, nil
+}
+
+// Checks the custom fields of a condition against the field definitions
+// of the account. The definitions are fetched only when the condition
+// refers to a custom field.
+func (client *Client) checkCondition(ctx context.Context, condition Condition) error {
+	if len(condition.custom) == 0 {
+		return nil
+	}
+
+	definitions, err := client.fieldDefinitions(ctx)
+	if err != nil {
+		return err
+	}
+
+	types := make(map[string]FieldType, len(definitions))
+	for _, definition := range definitions {
+		types[definition.Name] = definition.Type
+	}
+
+	for _, comparison := range condition.custom {
+		kind, ok := types[comparison.field]
+		if !ok {
+			return fmt.Errorf("The custom field '%s' is not defined.", comparison.field)
+		}
+		if !fieldOperators[kind][comparison.operator] {
+			return fmt.Errorf("The operator '%s' is not supported by the %s field '%s'.",
+				comparison.operator, kind, comparison.field)
+		}
+	}
+	return nil
+}
+
+func segmentLiteral(value interface{}) (string, error) {
+	switch value := value.(type) {
+	case string:
+		return quoteSegment(value), nil
+	case bool:
+		return fmt.Sprint(value), nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprint(value), nil
+	case time.Time:
+		return quoteSegment(value.UTC().Format(time.RFC3339)), nil
+	default:
+		return "", fmt.Errorf("the type %T is not supported", value)
+	}
+}
+
+func quoteSegment(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// Represents a v3 segment, a saved query over the contacts
+type Segment struct {
+	// The segment identificator
+	ID string `json:"id"`
+	// The segment name
+	Name string `json:"name"`
+	// The identificator of the list the segment selects from. Every
+	// contact is selected from when it is empty.
+	ParentListID string `json:"parent_list_id"`
+	// The segment query
+	Query string `json:"query_dsl"`
+	// The number of the matched contacts
+	ContactsCount uint64 `json:"contacts_count"`
+	// The time the segment was created at
+	CreatedAt time.Time `json:"created_at"`
+	// The time the segment was updated at
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// List all v3 segments on your account
+func (client *Client) Segments() ([]Segment, error) {
+	return client.SegmentPager().All(client.context())
+}
+
+// Page through the v3 segments on your account
+func (client *Client) SegmentPager() *Pager[Segment] {
+	return singlePager(client, "Segments", func(ctx context.Context) ([]Segment, error) {
+		if err := client.requireMarketing(); err != nil {
+			return nil, err
+		}
+
+		var result struct {
+			Results []Segment `json:"results"`
+		}
+		if err := client.call(ctx, http.MethodGet, "/marketing/segments", nil, &result); err != nil {
+			return nil, err
+		}
+		return result.Results, nil
+	})
+}
+
+// Get a v3 segment with its query
+func (client *Client) Segment(id string) (*Segment, error) {
+	ctx, span := client.startSpan("Segment")
+	defer span.End()
+
+	errorf := func(err error) error {
+		return client.errorf("Segment", err)
+	}
+
+	if err := client.requireMarketing(); err != nil {
+		return nil, errorf(err)
+	}
+
+	if id == "" {
+		return nil, errorf(errors.New("The segment id is empty."))
+	}
+
+	response, err := client.request(ctx, http.MethodGet, "/marketing/segments/"+url.PathEscape(id), nil)
+	if err != nil {
+		return nil, errorf(err)
+	}
+
+	segment := &Segment{}
+	if err := json.NewDecoder(response).Decode(segment); err != nil {
+		return nil, errorf(err)
+	}
+
+	return segment, nil
+}
+
+// Create a v3 segment of the members of a list matching a condition.
+// Every contact is matched when the list is empty.
+func (client *Client) CreateSegment(name, list string, condition Condition) (*Segment, error) {
+	ctx, span := client.startSpan("CreateSegment")
+	defer span.End()
+
+	errorf := func(err error) error {
+		return client.errorf("CreateSegment", err)
+	}
+
+	if err := client.requireMarketing(); err != nil {
+		return nil, errorf(err)
+	}
+
+	if name == "" {
+		return nil, errorf(errors.New("The segment name is empty."))
+	}
+
+	query, err := SegmentQuery(condition)
+	if err != nil {
+		return nil, errorf(err)
+	}
+
+	if err := client.checkCondition(ctx, condition); err != nil {
+		return nil, errorf(err)
+	}
+
+	body := map[string]string{"name": name, "query_dsl": query}
+	if list != "" {
+		found, err := (&marketingBackend{client: client}).find(ctx, list)
+		if err != nil {
+			return nil, errorf(err)
+		}
+		body["parent_list_id"] = found.ID
+	}
+
+	response, err := client.request(ctx, http.MethodPost, "/marketing/segments", body)
+	if err != nil {
+		return nil, errorf(err)
+	}
+
+	segment := &Segment{}
+	if err := json.NewDecoder(response).Decode(segment); err != nil {
+		return nil, errorf(err)
+	}
+
+	return segment, nil
+}
+
+// Change the name and the condition of a v3 segment
+func (client *Client) UpdateSegment(id, name string, condition Condition) (*Segment, error) {
+	ctx, span := client.startSpan("UpdateSegment")
+	defer span.End()
+
+	errorf := func(err error) error {
+		return client.errorf("UpdateSegment", err)
+	}
+
+	if err := client.requireMarketing(); err != nil {
+		return nil, errorf(err)
+	}
+
+	if id == "" {
+		return nil, errorf(errors.New("The segment id is empty."))
+	}
+
+	if name == "" {
+		return nil, errorf(errors.New("The segment name is empty."))
+	}
+
+	query, err := SegmentQuery(condition)
+	if err != nil {
+		return nil, errorf(err)
+	}
+
+	if err := client.checkCondition(ctx, condition); err != nil {
+		return nil, errorf(err)
+	}
+
+	body := map[string]string{"name": name, "query_dsl": query}
+	response, err := client.request(ctx, http.MethodPatch, "/marketing/segments/"+url.PathEscape(id), body)
+	if err != nil {
+		return nil, errorf(err)
+	}
+
+	segment := &Segment{}
+	if err := json.NewDecoder(response).Decode(segment); err != nil {
+		return nil, errorf(err)
+	}
+
+	return segment, nil
+}
+
+// Remove a v3 segment. The matched contacts are kept.
+func (client *Client) DeleteSegment(id string) error {
+	ctx, span := client.startSpan("DeleteSegment")
+	defer span.End()
+
+	errorf := func(err error) error {
+		return client.errorf("DeleteSegment", err)
+	}
+
+	if err := client.requireMarketing(); err != nil {
+		return errorf(err)
+	}
+
+	if id == "" {
+		return errorf(errors.New("The segment id is empty."))
+	}
+
+	if _, err := client.request(ctx, http.MethodDelete, "/marketing/segments/"+url.PathEscape(id), nil); err != nil {
+		return errorf(err)
+	}
+
+	return nil
+}
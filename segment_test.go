@@ -0,0 +1,163 @@
+package sendbit_test
+
+import (
+	"time"
+
+	. "github.com/svett/sendbit"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Segment", func() {
+	Describe("SegmentQuery", func() {
+		It("compiles the conditions", func() {
+			query, err := SegmentQuery(All(
+				CustomField("plan").Eq("pro"),
+				OpenedWithin(30),
+			))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(query).To(Equal("SELECT contact_id, updated_at FROM contact_data WHERE " +
+				"(plan = 'pro' AND contact_id IN (SELECT contact_id FROM email_activity " +
+				"WHERE event_type = 'opened' AND event_timestamp >= " +
+				"TIMESTAMP_SUB(CURRENT_TIMESTAMP(), INTERVAL 30 DAY)))"))
+		})
+
+		It("combines and quotes the values", func() {
+			created := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+			condition := Any(
+				Field("email").Like("%@example.com"),
+				Negate(Field("last_name").Eq("O'Brien")),
+				All(CustomField("score").Ge(4.5), Field("created_at").Lt(created)),
+				Field("city").IsNull(),
+				InList("list-1"),
+			)
+			Expect(condition.String()).To(Equal("(email LIKE '%@example.com' OR " +
+				"NOT (last_name = 'O''Brien') OR " +
+				"(score >= 4.5 AND created_at < '2024-03-01T00:00:00Z') OR " +
+				"city IS NULL OR " +
+				"CONTAINS(list_ids, 'list-1'))"))
+		})
+
+		Context("when a field is not reserved", func() {
+			It("fails to compile", func() {
+				_, err := SegmentQuery(All(Field("email").Eq("a"), Field("plan").Eq("pro")))
+				Expect(err).To(MatchError("The field 'plan' is not a reserved field."))
+			})
+		})
+
+		Context("when a custom field name is invalid", func() {
+			It("fails to compile", func() {
+				_, err := SegmentQuery(CustomField("plan; DROP").Eq("pro"))
+				Expect(err).To(MatchError("The field name 'plan; DROP' is invalid."))
+			})
+		})
+
+		Context("when a value type is not supported", func() {
+			It("fails to compile", func() {
+				_, err := SegmentQuery(Field("email").Eq([]string{"a"}))
+				Expect(err).To(MatchError("The value of the field 'email' is invalid: " +
+					"the type []string is not supported"))
+			})
+		})
+
+		Context("when the condition is empty", func() {
+			It("fails to compile", func() {
+				_, err := SegmentQuery(All())
+				Expect(err).To(MatchError("The segment condition is empty."))
+			})
+		})
+	})
+
+	Describe("CRUD", func() {
+		var (
+			fake   *FakeMarketing
+			client *Client
+			id     string
+		)
+
+		BeforeEach(func() {
+			fake = NewFakeMarketing()
+			id = fake.AddList("newsletter")
+			fake.AddField("plan", "Text")
+			fake.AddField("score", "Number")
+			client = fake.Client()
+		})
+
+		AfterEach(func() {
+			fake.Close()
+		})
+
+		It("manages the segments of a list", func() {
+			segment, err := client.CreateSegment("pro", "newsletter", CustomField("plan").Eq("pro"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(segment.ParentListID).To(Equal(id))
+			Expect(segment.Query).To(Equal("SELECT contact_id, updated_at FROM contact_data WHERE plan = 'pro'"))
+
+			updated, err := client.UpdateSegment(segment.ID, "free", CustomField("plan").Eq("free"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(updated.Name).To(Equal("free"))
+
+			fetched, err := client.Segment(segment.ID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fetched.Query).To(HaveSuffix("plan = 'free'"))
+
+			segments, err := client.Segments()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(segments).To(HaveLen(1))
+
+			Expect(client.DeleteSegment(segment.ID)).To(Succeed())
+			Expect(fake.Segments).To(BeEmpty())
+		})
+
+		Context("when the condition is invalid", func() {
+			It("does not send the segment", func() {
+				_, err := client.CreateSegment("pro", "", Field("plan").Eq("pro"))
+				Expect(err).To(MatchError("sendbit: client.CreateSegment error: " +
+					"The field 'plan' is not a reserved field."))
+				Expect(fake.Requests).To(BeEmpty())
+			})
+		})
+
+		Context("when the custom field is not defined", func() {
+			It("does not send the segment", func() {
+				_, err := client.CreateSegment("pro", "", All(
+					CustomField("plan").Eq("pro"),
+					CustomField("tier").Eq("gold"),
+				))
+				Expect(err).To(MatchError("sendbit: client.CreateSegment error: " +
+					"The custom field 'tier' is not defined."))
+				Expect(fake.Requests).To(Equal([]string{"GET /v3/marketing/field_definitions"}))
+			})
+		})
+
+		Context("when the operator does not suit the field type", func() {
+			It("does not send the segment", func() {
+				_, err := client.UpdateSegment("segment", "pro", Negate(CustomField("score").Like("4%")))
+				Expect(err).To(MatchError("sendbit: client.UpdateSegment error: " +
+					"The operator 'LIKE' is not supported by the Number field 'score'."))
+
+				_, err = client.CreateSegment("pro", "", CustomField("plan").Gt("pro"))
+				Expect(err).To(MatchError("sendbit: client.CreateSegment error: " +
+					"The operator '>' is not supported by the Text field 'plan'."))
+			})
+		})
+
+		Context("when the list does not exist", func() {
+			It("fails to create the segment", func() {
+				_, err := client.CreateSegment("pro", "unknown", Field("email").Like("%"))
+				Expect(err).To(MatchError("sendbit: client.CreateSegment error: " +
+					"the title(s) 'unknown' do not exist"))
+			})
+		})
+
+		Context("when the legacy API is used", func() {
+			It("fails to list the segments", func() {
+				client.API = LegacyAPI
+				_, err := client.Segments()
+				Expect(err).To(MatchError("sendbit: client.Segments error: " +
+					"The call requires the v3 Marketing Campaigns API."))
+			})
+		})
+	})
+})
@@ -1,7 +1,18 @@
 package sendbit_test
 
 import (
+	"encoding/json"
+	"fmt"
 	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	. "github.com/svett/sendbit"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -27,3 +38,975 @@ func RandomString(length int) string {
 	}
 	return string(wordmap)
 }
+
+// An in-memory SendGrid newsletter API used by the specs
+type FakeSendGrid struct {
+	*httptest.Server
+
+	mutex sync.Mutex
+	// The recipients of every list
+	Lists map[string][]Recipient
+	// The names of the lists in creation order
+	Names []string
+	// The paths of the received requests
+	Requests []string
+	// The form values of the received requests
+	Forms []url.Values
+	// The suppression records by kind such as "bounces"
+	Suppressions map[string][]map[string]string
+	// The global unsubscribes
+	Unsubscribes []string
+	// The daily statistics
+	Stats []map[string]interface{}
+	// The error messages returned for particular paths
+	Errors map[string]string
+
+	once map[string]string
+}
+
+func NewFakeSendGrid() *FakeSendGrid {
+	fake := &FakeSendGrid{
+		Lists:        map[string][]Recipient{},
+		Errors:       map[string]string{},
+		Suppressions: map[string][]map[string]string{},
+		once:         map[string]string{},
+	}
+	fake.Server = httptest.NewServer(http.HandlerFunc(fake.serve))
+	return fake
+}
+
+// Returns a client connected to the fake
+func (fake *FakeSendGrid) Client() *Client {
+	client, err := NewClient("user", "pass")
+	Expect(err).ToNot(HaveOccurred())
+	client.Host = fake.URL
+	return client
+}
+
+// Adds a list with its recipients
+func (fake *FakeSendGrid) AddList(name string, recipients ...Recipient) {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	if _, ok := fake.Lists[name]; !ok {
+		fake.Names = append(fake.Names, name)
+	}
+	fake.Lists[name] = append(fake.Lists[name], recipients...)
+}
+
+// Fails the next request of a particular path
+func (fake *FakeSendGrid) FailOnce(path, message string) {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	fake.once[path] = message
+}
+
+// Returns the recipients of a list
+func (fake *FakeSendGrid) Recipients(name string) []Recipient {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	return append([]Recipient(nil), fake.Lists[name]...)
+}
+
+func (fake *FakeSendGrid) serve(w http.ResponseWriter, r *http.Request) {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/api")
+	fake.Requests = append(fake.Requests, path)
+	message, ok := fake.Errors[path]
+	if !ok {
+		message, ok = fake.once[path]
+		delete(fake.once, path)
+	}
+	if ok {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": message})
+		return
+	}
+
+	r.ParseForm()
+	fake.Forms = append(fake.Forms, r.PostForm)
+	name := r.PostForm.Get("list")
+	recipients, exists := fake.Lists[name]
+	reply := func(value interface{}) {
+		json.NewEncoder(w).Encode(value)
+	}
+	notExist := func() {
+		w.WriteHeader(http.StatusUnauthorized)
+		reply(map[string]string{"error": fmt.Sprintf("the title(s) '%s' do not exist", name)})
+	}
+
+	switch path {
+	case "/newsletter/lists/add.json":
+		if exists {
+			reply(map[string]string{"error": fmt.Sprintf("%s already exists", name)})
+			return
+		}
+		fake.Names = append(fake.Names, name)
+		fake.Lists[name] = nil
+		reply(map[string]string{"message": "success"})
+	case "/newsletter/lists/delete.json":
+		if !exists {
+			notExist()
+			return
+		}
+		delete(fake.Lists, name)
+		for index, other := range fake.Names {
+			if other == name {
+				fake.Names = append(fake.Names[:index], fake.Names[index+1:]...)
+				break
+			}
+		}
+		reply(map[string]string{"message": "success"})
+	case "/newsletter/lists/edit.json":
+		if !exists {
+			notExist()
+			return
+		}
+		newName := r.PostForm.Get("newlist")
+		fake.Lists[newName] = recipients
+		delete(fake.Lists, name)
+		for index, other := range fake.Names {
+			if other == name {
+				fake.Names[index] = newName
+			}
+		}
+		reply(map[string]string{"message": "success"})
+	case "/newsletter/lists/get.json":
+		lists := []List{}
+		for index, other := range fake.Names {
+			if name == "" || name == other {
+				lists = append(lists, List{ID: uint64(index + 1), Name: other})
+			}
+		}
+		if name != "" && len(lists) == 0 {
+			notExist()
+			return
+		}
+		reply(lists)
+	case "/newsletter/lists/email/add.json":
+		if !exists {
+			notExist()
+			return
+		}
+		inserted := 0
+		for _, data := range append(r.PostForm["data"], r.PostForm["data[]"]...) {
+			var recipient Recipient
+			Expect(json.Unmarshal([]byte(data), &recipient)).To(Succeed())
+			if indexOf(recipients, recipient.Email) < 0 {
+				recipients = append(recipients, recipient)
+				inserted++
+			}
+		}
+		fake.Lists[name] = recipients
+		reply(map[string]int{"inserted": inserted})
+	case "/newsletter/lists/email/delete.json":
+		if !exists {
+			notExist()
+			return
+		}
+		removed := 0
+		for _, email := range r.PostForm["email[]"] {
+			if index := indexOf(recipients, email); index >= 0 {
+				recipients = append(recipients[:index], recipients[index+1:]...)
+				removed++
+			}
+		}
+		fake.Lists[name] = recipients
+		reply(map[string]int{"removed": removed})
+	case "/newsletter/lists/email/get.json":
+		if !exists {
+			notExist()
+			return
+		}
+		found := []Recipient{}
+		for _, recipient := range recipients {
+			if email := r.PostForm.Get("email"); email == "" || email == recipient.Email {
+				found = append(found, recipient)
+			}
+		}
+		reply(found)
+	case "/newsletter/lists/email/count.json":
+		if !exists {
+			notExist()
+			return
+		}
+		reply(map[string]int{"count": len(recipients)})
+	case "/stats.get.json":
+		rows := []map[string]interface{}{}
+		categories := r.PostForm["category[]"]
+		for _, row := range fake.Stats {
+			for _, category := range categories {
+				if row["category"] == category {
+					rows = append(rows, row)
+				}
+			}
+			if len(categories) == 0 {
+				rows = append(rows, row)
+			}
+		}
+		reply(rows)
+	case "/unsubscribes.add.json":
+		fake.Unsubscribes = append(fake.Unsubscribes, r.PostForm["email"]...)
+		reply(map[string]string{"message": "success"})
+	case "/unsubscribes.get.json":
+		records := []map[string]string{}
+		for _, email := range fake.Unsubscribes {
+			if filter := r.PostForm.Get("email"); filter == "" || filter == email {
+				records = append(records, map[string]string{"email": email, "created": "2015-06-01 10:00:00"})
+			}
+		}
+		reply(records)
+	case "/unsubscribes.delete.json":
+		for index, email := range fake.Unsubscribes {
+			if email == r.PostForm.Get("email") {
+				fake.Unsubscribes = append(fake.Unsubscribes[:index], fake.Unsubscribes[index+1:]...)
+				reply(map[string]string{"message": "success"})
+				return
+			}
+		}
+		reply(map[string]string{"error": "Email does not exist"})
+	case "/bounces.get.json", "/blocks.get.json", "/spamreports.get.json", "/invalidemails.get.json":
+		records := []map[string]string{}
+		for _, record := range fake.Suppressions[suppressionKind(path)] {
+			if email := r.PostForm.Get("email"); email == "" || email == record["email"] {
+				records = append(records, record)
+			}
+		}
+		offset, _ := strconv.Atoi(r.PostForm.Get("offset"))
+		records = records[min(offset, len(records)):]
+		if limit, err := strconv.Atoi(r.PostForm.Get("limit")); err == nil {
+			records = records[:min(limit, len(records))]
+		}
+		reply(records)
+	case "/bounces.delete.json", "/blocks.delete.json", "/spamreports.delete.json", "/invalidemails.delete.json":
+		kind := suppressionKind(path)
+		records := fake.Suppressions[kind]
+		for index, record := range records {
+			if record["email"] == r.PostForm.Get("email") {
+				fake.Suppressions[kind] = append(records[:index], records[index+1:]...)
+				reply(map[string]string{"message": "success"})
+				return
+			}
+		}
+		reply(map[string]string{"error": "Email does not exist"})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		reply(map[string]string{"error": "unknown endpoint " + path})
+	}
+}
+
+func suppressionKind(path string) string {
+	return strings.TrimPrefix(strings.SplitN(path, ".", 2)[0], "/")
+}
+
+func indexOf(recipients []Recipient, email string) int {
+	for index, recipient := range recipients {
+		if recipient.Email == email {
+			return index
+		}
+	}
+	return -1
+}
+
+// An in-memory SendGrid v3 Marketing Campaigns API used by the specs
+type FakeMarketing struct {
+	*httptest.Server
+
+	mutex sync.Mutex
+	// The API key expected as a bearer token
+	APIKey string
+	// The lists in creation order
+	Lists []FakeMarketingList
+	// The contacts keyed by email
+	Contacts map[string]*FakeContact
+	// The method and the path of the received requests
+	Requests []string
+	// The page size of the list endpoint
+	PageSize int
+	// The contacts jobs keyed by identifier
+	Jobs map[string]*FakeJob
+	// The number of polls a job stays pending for
+	PendingPolls int
+	// The segments in creation order
+	Segments []*FakeSegment
+	// The custom field definitions in creation order
+	Fields []FakeField
+	// The dynamic templates in creation order
+	Templates []*FakeTemplate
+	// The bodies of the sent emails
+	Mail []map[string]json.RawMessage
+	// The unsubscribe groups in creation order
+	Groups []*FakeGroup
+	// The suppressions keyed by the v3 kind such as "spam_reports"
+	Suppressions map[string][]map[string]interface{}
+	// The globally unsubscribed emails
+	Unsubscribes []string
+	// The daily statistics returned by the stats endpoints
+	Stats []map[string]interface{}
+	// The query of the last request
+	Query url.Values
+
+	sequence int
+}
+
+// A list of the v3 fake
+type FakeMarketingList struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// A contact of the v3 fake
+type FakeContact struct {
+	ID           string                 `json:"id"`
+	Email        string                 `json:"email"`
+	FirstName    string                 `json:"first_name,omitempty"`
+	LastName     string                 `json:"last_name,omitempty"`
+	ListIDs      []string               `json:"list_ids"`
+	CustomFields map[string]interface{} `json:"custom_fields,omitempty"`
+
+	// The fields sent by an upsert. Every field is set when it is nil.
+	sent map[string]json.RawMessage
+}
+
+// A contacts job of the v3 fake
+type FakeJob struct {
+	ID      string         `json:"id"`
+	Type    string         `json:"job_type"`
+	Status  string         `json:"status"`
+	Results map[string]int `json:"results"`
+	Polls   int            `json:"-"`
+}
+
+// A custom field definition of the v3 fake
+type FakeField struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"field_type"`
+}
+
+// Defines a custom field and returns its identifier
+func (fake *FakeMarketing) AddField(name, kind string) string {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	field := FakeField{ID: fake.nextID("field"), Name: name, Type: kind}
+	fake.Fields = append(fake.Fields, field)
+	return field.ID
+}
+
+// A dynamic template of the v3 fake
+type FakeTemplate struct {
+	ID         string         `json:"id"`
+	Name       string         `json:"name"`
+	Generation string         `json:"generation"`
+	Versions   []*FakeVersion `json:"versions"`
+}
+
+// A template version of the v3 fake
+type FakeVersion struct {
+	ID         string `json:"id"`
+	TemplateID string `json:"template_id"`
+	Name       string `json:"name"`
+	Subject    string `json:"subject"`
+	HTML       string `json:"html_content"`
+	Text       string `json:"plain_content"`
+	Active     int    `json:"active"`
+}
+
+// An unsubscribe group of the v3 fake
+type FakeGroup struct {
+	ID           int64    `json:"id"`
+	Name         string   `json:"name"`
+	Description  string   `json:"description"`
+	IsDefault    bool     `json:"is_default"`
+	Unsubscribes int      `json:"unsubscribes"`
+	Emails       []string `json:"-"`
+}
+
+// A segment of the v3 fake
+type FakeSegment struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	ParentListID string `json:"parent_list_id,omitempty"`
+	QueryDSL     string `json:"query_dsl"`
+}
+
+func NewFakeMarketing() *FakeMarketing {
+	fake := &FakeMarketing{
+		APIKey:   "SG.key",
+		Contacts: map[string]*FakeContact{},
+		PageSize: 1000,
+		Jobs:     map[string]*FakeJob{},
+
+		Suppressions: map[string][]map[string]interface{}{},
+	}
+	fake.Server = httptest.NewServer(http.HandlerFunc(fake.serve))
+	return fake
+}
+
+// Returns a v3 client connected to the fake
+func (fake *FakeMarketing) Client() *Client {
+	client, err := NewClient("apikey", fake.APIKey, WithAPI(MarketingAPI))
+	Expect(err).ToNot(HaveOccurred())
+	client.Host = fake.URL
+	return client
+}
+
+// Adds a list with its contacts and returns the list identifier
+func (fake *FakeMarketing) AddList(name string, recipients ...Recipient) string {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	id := fake.nextID("list")
+	fake.Lists = append(fake.Lists, FakeMarketingList{ID: id, Name: name})
+	for _, recipient := range recipients {
+		names := strings.SplitN(recipient.Name, " ", 2)
+		contact := &FakeContact{Email: recipient.Email, FirstName: names[0]}
+		if len(names) == 2 {
+			contact.LastName = names[1]
+		}
+		for field, value := range recipient.Fields {
+			if contact.CustomFields == nil {
+				contact.CustomFields = map[string]interface{}{}
+			}
+			contact.CustomFields[field] = value
+		}
+		fake.upsert(contact, id)
+	}
+	return id
+}
+
+// Returns the emails of the contacts of a list
+func (fake *FakeMarketing) Members(id string) []string {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	members := []string{}
+	for email, contact := range fake.Contacts {
+		if hasString(contact.ListIDs, id) {
+			members = append(members, email)
+		}
+	}
+	return members
+}
+
+func (fake *FakeMarketing) nextID(prefix string) string {
+	fake.sequence++
+	return fmt.Sprintf("%s-%04d", prefix, fake.sequence)
+}
+
+func (fake *FakeMarketing) upsert(contact *FakeContact, listIDs ...string) {
+	email := strings.ToLower(contact.Email)
+	existing, ok := fake.Contacts[email]
+	if !ok {
+		existing = &FakeContact{ID: fake.nextID("contact"), Email: email, ListIDs: []string{}}
+		fake.Contacts[email] = existing
+	}
+	// The upsert changes only the sent fields as the API does
+	sent := func(name string) bool {
+		_, ok := contact.sent[name]
+		return contact.sent == nil || ok
+	}
+	if sent("first_name") {
+		existing.FirstName = contact.FirstName
+	}
+	if sent("last_name") {
+		existing.LastName = contact.LastName
+	}
+	for name, value := range contact.CustomFields {
+		if existing.CustomFields == nil {
+			existing.CustomFields = map[string]interface{}{}
+		}
+		if value == nil {
+			delete(existing.CustomFields, name)
+			continue
+		}
+		existing.CustomFields[name] = value
+	}
+	if len(existing.CustomFields) == 0 {
+		existing.CustomFields = nil
+	}
+	for _, id := range listIDs {
+		if !hasString(existing.ListIDs, id) {
+			existing.ListIDs = append(existing.ListIDs, id)
+		}
+	}
+}
+
+func (fake *FakeMarketing) serve(w http.ResponseWriter, r *http.Request) {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+
+	fake.Requests = append(fake.Requests, r.Method+" "+r.URL.Path)
+	fake.Query = r.URL.Query()
+	reply := func(status int, value interface{}) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		if value != nil {
+			json.NewEncoder(w).Encode(value)
+		}
+	}
+	fail := func(status int, message string) {
+		reply(status, map[string]interface{}{
+			"errors": []map[string]string{{"field": "", "message": message}},
+		})
+	}
+
+	if r.Header.Get("Authorization") != "Bearer "+fake.APIKey {
+		fail(http.StatusUnauthorized, "authorization required")
+		return
+	}
+
+	var body map[string]json.RawMessage
+	if r.Body != nil {
+		json.NewDecoder(r.Body).Decode(&body)
+	}
+	decode := func(name string, value interface{}) {
+		Expect(json.Unmarshal(body[name], value)).To(Succeed())
+	}
+	findList := func(id string) int {
+		for index, list := range fake.Lists {
+			if list.ID == id {
+				return index
+			}
+		}
+		return -1
+	}
+
+	segments := strings.Split(strings.TrimPrefix(r.URL.Path, "/v3/marketing/"), "/")
+	switch {
+	case r.Method == "GET" && r.URL.Path == "/v3/marketing/lists":
+		start, end, metadata := fake.page(r, len(fake.Lists))
+		reply(http.StatusOK, map[string]interface{}{"result": fake.Lists[start:end], "_metadata": metadata})
+	case r.Method == "POST" && r.URL.Path == "/v3/marketing/lists":
+		var name string
+		decode("name", &name)
+		list := FakeMarketingList{ID: fake.nextID("list"), Name: name}
+		fake.Lists = append(fake.Lists, list)
+		reply(http.StatusCreated, list)
+	case segments[0] == "lists" && len(segments) == 2:
+		index := findList(segments[1])
+		if index < 0 {
+			fail(http.StatusNotFound, "list not found")
+			return
+		}
+		switch r.Method {
+		case "PATCH":
+			decode("name", &fake.Lists[index].Name)
+			reply(http.StatusOK, fake.Lists[index])
+		case "DELETE":
+			fake.Lists = append(fake.Lists[:index], fake.Lists[index+1:]...)
+			reply(http.StatusNoContent, nil)
+		}
+	case segments[0] == "lists" && len(segments) == 4 && r.Method == "GET":
+		count := 0
+		for _, contact := range fake.Contacts {
+			if hasString(contact.ListIDs, segments[1]) {
+				count++
+			}
+		}
+		reply(http.StatusOK, map[string]int{"contact_count": count, "billable_count": count})
+	case segments[0] == "lists" && len(segments) == 3 && r.Method == "DELETE":
+		for _, id := range strings.Split(r.URL.Query().Get("contact_ids"), ",") {
+			for _, contact := range fake.Contacts {
+				if contact.ID != id {
+					continue
+				}
+				for index, listID := range contact.ListIDs {
+					if listID == segments[1] {
+						contact.ListIDs = append(contact.ListIDs[:index], contact.ListIDs[index+1:]...)
+						break
+					}
+				}
+			}
+		}
+		reply(http.StatusAccepted, map[string]string{"job_id": fake.nextID("job")})
+	case r.Method == "PUT" && r.URL.Path == "/v3/marketing/contacts":
+		var listIDs []string
+		var contacts []*FakeContact
+		var sent []map[string]json.RawMessage
+		decode("list_ids", &listIDs)
+		decode("contacts", &contacts)
+		decode("contacts", &sent)
+		for index, contact := range contacts {
+			contact.sent = sent[index]
+		}
+		for _, id := range listIDs {
+			if findList(id) < 0 {
+				fail(http.StatusBadRequest, "list "+id+" does not exist")
+				return
+			}
+		}
+		for _, contact := range contacts {
+			named := map[string]interface{}{}
+			for id, value := range contact.CustomFields {
+				found := false
+				for _, field := range fake.Fields {
+					if field.ID == id {
+						named[field.Name] = value
+						found = true
+					}
+				}
+				if !found {
+					fail(http.StatusBadRequest, "custom field "+id+" does not exist")
+					return
+				}
+			}
+			if len(named) > 0 {
+				contact.CustomFields = named
+			}
+		}
+		job := &FakeJob{ID: fake.nextID("job"), Type: "upsert", Status: "pending", Results: map[string]int{
+			"requested_count": len(contacts),
+		}}
+		for _, contact := range contacts {
+			if _, ok := fake.Contacts[strings.ToLower(contact.Email)]; ok {
+				job.Results["updated_count"]++
+			} else {
+				job.Results["created_count"]++
+			}
+			fake.upsert(contact, listIDs...)
+		}
+		fake.Jobs[job.ID] = job
+		reply(http.StatusAccepted, map[string]string{"job_id": job.ID})
+	case r.Method == "DELETE" && r.URL.Path == "/v3/marketing/contacts":
+		for _, id := range strings.Split(r.URL.Query().Get("ids"), ",") {
+			for email, contact := range fake.Contacts {
+				if contact.ID == id {
+					delete(fake.Contacts, email)
+				}
+			}
+		}
+		reply(http.StatusAccepted, map[string]string{"job_id": fake.nextID("job")})
+	case r.Method == "GET" && segments[0] == "contacts" && len(segments) == 3 && segments[1] == "imports":
+		job, ok := fake.Jobs[segments[2]]
+		if !ok {
+			fail(http.StatusNotFound, "job not found")
+			return
+		}
+		if job.Polls++; job.Status == "pending" && job.Polls > fake.PendingPolls {
+			job.Status = "completed"
+		}
+		reply(http.StatusOK, job)
+	case r.Method == "POST" && r.URL.Path == "/v3/marketing/contacts/search/emails":
+		var emails []string
+		decode("emails", &emails)
+		result := map[string]interface{}{}
+		found := false
+		for _, email := range emails {
+			if contact, ok := fake.Contacts[strings.ToLower(email)]; ok {
+				result[email] = map[string]interface{}{"contact": contact}
+				found = true
+			} else {
+				result[email] = map[string]string{"error": "contact not found"}
+			}
+		}
+		if !found {
+			fail(http.StatusNotFound, "contacts not found")
+			return
+		}
+		reply(http.StatusOK, map[string]interface{}{"result": result})
+	case r.Method == "POST" && r.URL.Path == "/v3/marketing/contacts/search":
+		var query string
+		decode("query", &query)
+		id := strings.Split(query, "'")[1]
+		contacts := []*FakeContact{}
+		for _, contact := range fake.Contacts {
+			if hasString(contact.ListIDs, id) {
+				contacts = append(contacts, contact)
+			}
+		}
+		sort.Slice(contacts, func(i, j int) bool { return contacts[i].ID < contacts[j].ID })
+		start, end, metadata := fake.page(r, len(contacts))
+		reply(http.StatusOK, map[string]interface{}{
+			"result":        contacts[start:end],
+			"contact_count": len(contacts),
+			"_metadata":     metadata,
+		})
+	case r.Method == "GET" && r.URL.Path == "/v3/marketing/field_definitions":
+		reply(http.StatusOK, map[string]interface{}{
+			"custom_fields":   fake.Fields,
+			"reserved_fields": []FakeField{{ID: "_rf0_T", Name: "email", Type: "Text"}},
+		})
+	case r.Method == "POST" && r.URL.Path == "/v3/marketing/field_definitions":
+		field := FakeField{ID: fake.nextID("field")}
+		decode("name", &field.Name)
+		decode("field_type", &field.Type)
+		fake.Fields = append(fake.Fields, field)
+		reply(http.StatusOK, field)
+	case r.Method == "DELETE" && segments[0] == "field_definitions" && len(segments) == 2:
+		for index, field := range fake.Fields {
+			if field.ID == segments[1] {
+				fake.Fields = append(fake.Fields[:index], fake.Fields[index+1:]...)
+				reply(http.StatusNoContent, nil)
+				return
+			}
+		}
+		fail(http.StatusNotFound, "custom field not found")
+	case r.URL.Path == "/v3/asm/groups":
+		if r.Method == "GET" {
+			reply(http.StatusOK, fake.Groups)
+			return
+		}
+		fake.sequence++
+		group := &FakeGroup{ID: int64(fake.sequence)}
+		decode("name", &group.Name)
+		decode("description", &group.Description)
+		decode("is_default", &group.IsDefault)
+		fake.Groups = append(fake.Groups, group)
+		reply(http.StatusCreated, group)
+	case strings.HasPrefix(r.URL.Path, "/v3/asm/groups/"):
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/v3/asm/groups/"), "/")
+		index := -1
+		for position, group := range fake.Groups {
+			if fmt.Sprint(group.ID) == parts[0] {
+				index = position
+			}
+		}
+		if index < 0 {
+			fail(http.StatusNotFound, "group not found")
+			return
+		}
+		group := fake.Groups[index]
+		switch {
+		case len(parts) == 1 && r.Method == "GET":
+			reply(http.StatusOK, group)
+		case len(parts) == 1 && r.Method == "PATCH":
+			decode("name", &group.Name)
+			decode("description", &group.Description)
+			decode("is_default", &group.IsDefault)
+			reply(http.StatusOK, group)
+		case len(parts) == 1 && r.Method == "DELETE":
+			fake.Groups = append(fake.Groups[:index], fake.Groups[index+1:]...)
+			reply(http.StatusNoContent, nil)
+		case len(parts) == 2 && r.Method == "GET":
+			reply(http.StatusOK, append([]string{}, group.Emails...))
+		case len(parts) == 2 && r.Method == "POST":
+			var emails []string
+			decode("recipient_emails", &emails)
+			for _, email := range emails {
+				if !hasString(group.Emails, email) {
+					group.Emails = append(group.Emails, email)
+				}
+			}
+			group.Unsubscribes = len(group.Emails)
+			reply(http.StatusCreated, map[string][]string{"recipient_emails": emails})
+		case len(parts) == 3 && r.Method == "DELETE":
+			for position, email := range group.Emails {
+				if email == parts[2] {
+					group.Emails = append(group.Emails[:position], group.Emails[position+1:]...)
+				}
+			}
+			group.Unsubscribes = len(group.Emails)
+			reply(http.StatusNoContent, nil)
+		}
+	case r.URL.Path == "/v3/asm/suppressions/global":
+		var emails []string
+		decode("recipient_emails", &emails)
+		for _, email := range emails {
+			if !hasString(fake.Unsubscribes, email) {
+				fake.Unsubscribes = append(fake.Unsubscribes, email)
+			}
+		}
+		reply(http.StatusCreated, map[string][]string{"recipient_emails": emails})
+	case strings.HasPrefix(r.URL.Path, "/v3/asm/suppressions/global/"):
+		email := strings.TrimPrefix(r.URL.Path, "/v3/asm/suppressions/global/")
+		position := -1
+		for index, unsubscribed := range fake.Unsubscribes {
+			if unsubscribed == email {
+				position = index
+			}
+		}
+		switch {
+		case r.Method == "DELETE" && position >= 0:
+			fake.Unsubscribes = append(fake.Unsubscribes[:position], fake.Unsubscribes[position+1:]...)
+			reply(http.StatusNoContent, nil)
+		case r.Method == "DELETE":
+			reply(http.StatusNoContent, nil)
+		case position >= 0:
+			reply(http.StatusOK, map[string]string{"recipient_email": email})
+		default:
+			reply(http.StatusOK, map[string]string{})
+		}
+	case strings.HasPrefix(r.URL.Path, "/v3/suppression/"):
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/v3/suppression/"), "/")
+		records := fake.Suppressions[parts[0]]
+		if parts[0] == "unsubscribes" {
+			records = nil
+			for _, email := range fake.Unsubscribes {
+				records = append(records, map[string]interface{}{"email": email, "created": 1433116800})
+			}
+		}
+		matches := []map[string]interface{}{}
+		kept := []map[string]interface{}{}
+		for _, record := range records {
+			if len(parts) == 1 || record["email"] == parts[1] {
+				matches = append(matches, record)
+			} else {
+				kept = append(kept, record)
+			}
+		}
+		if r.Method == "DELETE" {
+			fake.Suppressions[parts[0]] = kept
+			reply(http.StatusNoContent, nil)
+			return
+		}
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		offset = min(offset, len(matches))
+		matches = matches[offset:]
+		if limit, _ := strconv.Atoi(r.URL.Query().Get("limit")); limit > 0 && limit < len(matches) {
+			matches = matches[:limit]
+		}
+		reply(http.StatusOK, matches)
+	case r.Method == "GET" && (r.URL.Path == "/v3/stats" || r.URL.Path == "/v3/categories/stats"):
+		reply(http.StatusOK, fake.Stats)
+	case r.Method == "POST" && r.URL.Path == "/v3/mail/send":
+		fake.Mail = append(fake.Mail, body)
+		reply(http.StatusAccepted, nil)
+	case r.URL.Path == "/v3/templates":
+		if r.Method == "GET" {
+			reply(http.StatusOK, map[string]interface{}{"result": fake.Templates})
+			return
+		}
+		template := &FakeTemplate{ID: fake.nextID("d-template"), Versions: []*FakeVersion{}}
+		decode("name", &template.Name)
+		decode("generation", &template.Generation)
+		fake.Templates = append(fake.Templates, template)
+		reply(http.StatusCreated, template)
+	case strings.HasPrefix(r.URL.Path, "/v3/templates/"):
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/v3/templates/"), "/")
+		index := -1
+		for position, template := range fake.Templates {
+			if template.ID == parts[0] {
+				index = position
+			}
+		}
+		if index < 0 {
+			fail(http.StatusNotFound, "template not found")
+			return
+		}
+		template := fake.Templates[index]
+		activate := func(version *FakeVersion) {
+			for _, other := range template.Versions {
+				other.Active = 0
+			}
+			version.Active = 1
+		}
+		update := func(version *FakeVersion) {
+			decode("name", &version.Name)
+			decode("subject", &version.Subject)
+			decode("html_content", &version.HTML)
+			decode("plain_content", &version.Text)
+			if _, ok := body["active"]; !ok {
+				return
+			}
+			var active int
+			decode("active", &active)
+			if active == 1 {
+				activate(version)
+			} else {
+				version.Active = 0
+			}
+		}
+		switch {
+		case len(parts) == 1 && r.Method == "GET":
+			reply(http.StatusOK, template)
+		case len(parts) == 1 && r.Method == "PATCH":
+			decode("name", &template.Name)
+			reply(http.StatusOK, template)
+		case len(parts) == 1 && r.Method == "DELETE":
+			fake.Templates = append(fake.Templates[:index], fake.Templates[index+1:]...)
+			reply(http.StatusNoContent, nil)
+		case len(parts) == 2 && r.Method == "POST":
+			version := &FakeVersion{ID: fake.nextID("version"), TemplateID: template.ID}
+			template.Versions = append(template.Versions, version)
+			update(version)
+			reply(http.StatusCreated, version)
+		default:
+			position := -1
+			for other, version := range template.Versions {
+				if version.ID == parts[2] {
+					position = other
+				}
+			}
+			if position < 0 {
+				fail(http.StatusNotFound, "version not found")
+				return
+			}
+			version := template.Versions[position]
+			switch {
+			case len(parts) == 4 && r.Method == "POST":
+				activate(version)
+			case r.Method == "PATCH":
+				update(version)
+			case r.Method == "DELETE":
+				template.Versions = append(template.Versions[:position], template.Versions[position+1:]...)
+				reply(http.StatusNoContent, nil)
+				return
+			}
+			reply(http.StatusOK, version)
+		}
+	case r.Method == "GET" && r.URL.Path == "/v3/marketing/segments":
+		reply(http.StatusOK, map[string]interface{}{"results": fake.Segments})
+	case r.Method == "POST" && r.URL.Path == "/v3/marketing/segments":
+		segment := &FakeSegment{ID: fake.nextID("segment")}
+		decode("name", &segment.Name)
+		decode("query_dsl", &segment.QueryDSL)
+		if _, ok := body["parent_list_id"]; ok {
+			decode("parent_list_id", &segment.ParentListID)
+		}
+		fake.Segments = append(fake.Segments, segment)
+		reply(http.StatusCreated, segment)
+	case segments[0] == "segments" && len(segments) == 2:
+		index := -1
+		for position, segment := range fake.Segments {
+			if segment.ID == segments[1] {
+				index = position
+			}
+		}
+		if index < 0 {
+			fail(http.StatusNotFound, "segment not found")
+			return
+		}
+		switch r.Method {
+		case "GET":
+			reply(http.StatusOK, fake.Segments[index])
+		case "PATCH":
+			decode("name", &fake.Segments[index].Name)
+			decode("query_dsl", &fake.Segments[index].QueryDSL)
+			reply(http.StatusOK, fake.Segments[index])
+		case "DELETE":
+			fake.Segments = append(fake.Segments[:index], fake.Segments[index+1:]...)
+			reply(http.StatusNoContent, nil)
+		}
+	default:
+		fail(http.StatusNotFound, "unknown endpoint "+r.Method+" "+r.URL.Path)
+	}
+}
+
+// Returns the bounds of the requested page of a collection along with
+// the page metadata. The page size is capped at PageSize.
+func (fake *FakeMarketing) page(r *http.Request, total int) (int, int, map[string]interface{}) {
+	size := fake.PageSize
+	if requested, err := strconv.Atoi(r.URL.Query().Get("page_size")); err == nil && requested < size {
+		size = requested
+	}
+
+	start := 0
+	fmt.Sscan(r.URL.Query().Get("page_token"), &start)
+	start = min(start, total)
+	end := min(start+size, total)
+
+	metadata := map[string]interface{}{"count": total}
+	if end < total {
+		metadata["next"] = fmt.Sprintf("%s%s?page_size=%d&page_token=%d", fake.URL, r.URL.Path, size, end)
+	}
+	return start, end, metadata
+}
+
+func hasString(values []string, value string) bool {
+	for _, other := range values {
+		if other == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,271 @@
+package sendbit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Represents a SendGrid date such as "2015-06-01"
+type Date struct {
+	time.Time
+}
+
+func (date Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(date.Format(DateLayout))
+}
+
+func (date *Date) UnmarshalJSON(body []byte) error {
+	var text string
+	if err := json.Unmarshal(body, &text); err != nil {
+		return err
+	}
+
+	value, err := time.Parse(DateLayout, text)
+	if err != nil {
+		return err
+	}
+	date.Time = value
+	return nil
+}
+
+// Filters the email statistics
+type StatsQuery struct {
+	// StartDate - The first day of the statistics
+	StartDate time.Time
+	// EndDate - The last day of the statistics. It requires StartDate.
+	EndDate time.Time
+	// Days - The number of days before today. It is ignored when
+	// StartDate is set.
+	Days int
+	// Categories - Returns the statistics of particular categories only
+	Categories []string
+}
+
+// Represents the email statistics of a single day
+type DailyStats struct {
+	// The day of the statistics
+	Date Date `json:"date"`
+	// The category of the statistics. It is empty when the
+	// statistics are not filtered by category.
+	Category string `json:"category,omitempty"`
+	// The number of the emails requested to be sent
+	Requests uint64 `json:"requests"`
+	// The number of the delivered emails
+	Delivered uint64 `json:"delivered"`
+	// The number of the opens
+	Opens uint64 `json:"opens"`
+	// The number of the recipients who opened an email
+	UniqueOpens uint64 `json:"unique_opens"`
+	// The number of the clicks
+	Clicks uint64 `json:"clicks"`
+	// The number of the recipients who clicked a link
+	UniqueClicks uint64 `json:"unique_clicks"`
+	// The number of the bounced emails
+	Bounces uint64 `json:"bounces"`
+	// The number of the blocked emails
+	Blocked uint64 `json:"blocked"`
+	// The number of the emails sent to invalid addresses
+	InvalidEmails uint64 `json:"invalid_email"`
+	// The number of the spam reports
+	SpamReports uint64 `json:"spamreports"`
+	// The number of the unsubscribes
+	Unsubscribes uint64 `json:"unsubscribes"`
+}
+
+// The ratio of the delivered emails to the requested ones
+func (stats DailyStats) DeliveryRate() float64 {
+	return rate(stats.Delivered, stats.Requests)
+}
+
+// The ratio of the unique opens to the delivered emails
+func (stats DailyStats) OpenRate() float64 {
+	return rate(stats.UniqueOpens, stats.Delivered)
+}
+
+// The ratio of the unique clicks to the delivered emails
+func (stats DailyStats) ClickRate() float64 {
+	return rate(stats.UniqueClicks, stats.Delivered)
+}
+
+// The ratio of the bounced emails to the requested ones
+func (stats DailyStats) BounceRate() float64 {
+	return rate(stats.Bounces, stats.Requests)
+}
+
+// The ratio of the spam reports to the delivered emails
+func (stats DailyStats) SpamReportRate() float64 {
+	return rate(stats.SpamReports, stats.Delivered)
+}
+
+// The ratio of the unsubscribes to the delivered emails
+func (stats DailyStats) UnsubscribeRate() float64 {
+	return rate(stats.Unsubscribes, stats.Delivered)
+}
+
+func rate(count, total uint64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(count) / float64(total)
+}
+
+// Represents the daily email statistics ordered by date
+type StatsRows []DailyStats
+
+// Adds up the statistics of every row. The date and the category of
+// the result are empty.
+func (rows StatsRows) Sum() DailyStats {
+	total := DailyStats{}
+	for _, row := range rows {
+		total.Requests += row.Requests
+		total.Delivered += row.Delivered
+		total.Opens += row.Opens
+		total.UniqueOpens += row.UniqueOpens
+		total.Clicks += row.Clicks
+		total.UniqueClicks += row.UniqueClicks
+		total.Bounces += row.Bounces
+		total.Blocked += row.Blocked
+		total.InvalidEmails += row.InvalidEmails
+		total.SpamReports += row.SpamReports
+		total.Unsubscribes += row.Unsubscribes
+	}
+	return total
+}
+
+// Groups the rows by category
+func (rows StatsRows) ByCategory() map[string]StatsRows {
+	groups := map[string]StatsRows{}
+	for _, row := range rows {
+		groups[row.Category] = append(groups[row.Category], row)
+	}
+	return groups
+}
+
+// Retrieve the daily email statistics
+func (client *Client) Stats(query *StatsQuery) (StatsRows, error) {
+	ctx, span := client.startSpan("Stats")
+	defer span.End()
+
+	errorf := func(err error) error {
+		return client.errorf("Stats", err)
+	}
+
+	if query == nil {
+		query = &StatsQuery{}
+	}
+
+	if !query.EndDate.IsZero() && query.StartDate.IsZero() {
+		return nil, errorf(errors.New("The end date requires a start date."))
+	}
+
+	if client.API == MarketingAPI {
+		rows, err := client.marketingStats(ctx, query)
+		if err != nil {
+			return nil, errorf(err)
+		}
+		return rows, nil
+	}
+
+	data := url.Values{}
+	if !query.StartDate.IsZero() {
+		data.Add("start_date", query.StartDate.Format(DateLayout))
+	} else if query.Days > 0 {
+		data.Add("days", strconv.Itoa(query.Days))
+	}
+	if !query.EndDate.IsZero() {
+		data.Add("end_date", query.EndDate.Format(DateLayout))
+	}
+	for _, category := range query.Categories {
+		data.Add("category[]", category)
+	}
+
+	response, err := client.post(ctx, "/stats.get.json", data)
+	if err != nil {
+		return nil, errorf(err)
+	}
+
+	var rows StatsRows
+	if err := json.NewDecoder(response).Decode(&rows); err != nil {
+		return nil, errorf(err)
+	}
+
+	return rows, nil
+}
+
+// The statistics of a day in the v3 API, one entry per category
+type marketingStats struct {
+	Date  Date `json:"date"`
+	Stats []struct {
+		Name    string `json:"name"`
+		Metrics struct {
+			Requests      uint64 `json:"requests"`
+			Delivered     uint64 `json:"delivered"`
+			Opens         uint64 `json:"opens"`
+			UniqueOpens   uint64 `json:"unique_opens"`
+			Clicks        uint64 `json:"clicks"`
+			UniqueClicks  uint64 `json:"unique_clicks"`
+			Bounces       uint64 `json:"bounces"`
+			Blocks        uint64 `json:"blocks"`
+			InvalidEmails uint64 `json:"invalid_emails"`
+			SpamReports   uint64 `json:"spam_reports"`
+			Unsubscribes  uint64 `json:"unsubscribes"`
+		} `json:"metrics"`
+	} `json:"stats"`
+}
+
+// Retrieves the statistics from the v3 API, which requires the start
+// date. It defaults to today when neither StartDate nor Days is set.
+func (client *Client) marketingStats(ctx context.Context, query *StatsQuery) (StatsRows, error) {
+	start := query.StartDate
+	if start.IsZero() {
+		start = time.Now().UTC().AddDate(0, 0, -query.Days)
+	}
+
+	values := url.Values{}
+	values.Add("start_date", start.Format(DateLayout))
+	if !query.EndDate.IsZero() {
+		values.Add("end_date", query.EndDate.Format(DateLayout))
+	}
+	values.Add("aggregated_by", "day")
+
+	path := "/stats"
+	if len(query.Categories) > 0 {
+		path = "/categories/stats"
+		for _, category := range query.Categories {
+			values.Add("categories", category)
+		}
+	}
+
+	var days []marketingStats
+	if err := client.call(ctx, http.MethodGet, path+"?"+values.Encode(), nil, &days); err != nil {
+		return nil, err
+	}
+
+	rows := StatsRows{}
+	for _, day := range days {
+		for _, stats := range day.Stats {
+			metrics := stats.Metrics
+			rows = append(rows, DailyStats{
+				Date:          day.Date,
+				Category:      stats.Name,
+				Requests:      metrics.Requests,
+				Delivered:     metrics.Delivered,
+				Opens:         metrics.Opens,
+				UniqueOpens:   metrics.UniqueOpens,
+				Clicks:        metrics.Clicks,
+				UniqueClicks:  metrics.UniqueClicks,
+				Bounces:       metrics.Bounces,
+				Blocked:       metrics.Blocks,
+				InvalidEmails: metrics.InvalidEmails,
+				SpamReports:   metrics.SpamReports,
+				Unsubscribes:  metrics.Unsubscribes,
+			})
+		}
+	}
+	return rows, nil
+}
@@ -0,0 +1,137 @@
+package sendbit_test
+
+import (
+	"net/url"
+	"time"
+
+	. "github.com/svett/sendbit"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Stats", func() {
+	var (
+		fake   *FakeSendGrid
+		client *Client
+	)
+
+	BeforeEach(func() {
+		fake = NewFakeSendGrid()
+		fake.Stats = []map[string]interface{}{
+			{"date": "2015-06-01", "category": "welcome", "requests": 100, "delivered": 90, "bounces": 10,
+				"opens": 60, "unique_opens": 45, "clicks": 20, "unique_clicks": 18, "spamreports": 1, "unsubscribes": 3},
+			{"date": "2015-06-02", "category": "welcome", "requests": 100, "delivered": 100,
+				"opens": 70, "unique_opens": 45, "clicks": 5, "unique_clicks": 2, "unsubscribes": 2},
+			{"date": "2015-06-01", "category": "billing", "requests": 10, "delivered": 10, "unique_opens": 9},
+		}
+		client = fake.Client()
+	})
+
+	AfterEach(func() {
+		fake.Close()
+	})
+
+	It("returns the typed daily rows", func() {
+		rows, err := client.Stats(&StatsQuery{Categories: []string{"welcome"}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rows).To(HaveLen(2))
+		Expect(rows[0]).To(Equal(DailyStats{
+			Date:         Date{time.Date(2015, 6, 1, 0, 0, 0, 0, time.UTC)},
+			Category:     "welcome",
+			Requests:     100,
+			Delivered:    90,
+			Opens:        60,
+			UniqueOpens:  45,
+			Clicks:       20,
+			UniqueClicks: 18,
+			Bounces:      10,
+			SpamReports:  1,
+			Unsubscribes: 3,
+		}))
+	})
+
+	It("sends the query", func() {
+		_, err := client.Stats(&StatsQuery{
+			StartDate:  time.Date(2015, 6, 1, 0, 0, 0, 0, time.UTC),
+			EndDate:    time.Date(2015, 6, 2, 0, 0, 0, 0, time.UTC),
+			Categories: []string{"welcome", "billing"},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fake.Forms[0]).To(Equal(url.Values{
+			"api_user":   {"user"},
+			"api_key":    {"pass"},
+			"start_date": {"2015-06-01"},
+			"end_date":   {"2015-06-02"},
+			"category[]": {"welcome", "billing"},
+		}))
+	})
+
+	Context("when the end date is set without a start date", func() {
+		It("fails to query", func() {
+			_, err := client.Stats(&StatsQuery{Days: 7, EndDate: time.Date(2015, 6, 2, 0, 0, 0, 0, time.UTC)})
+			Expect(err).To(MatchError("sendbit: client.Stats error: The end date requires a start date."))
+			Expect(fake.Forms).To(BeEmpty())
+		})
+	})
+
+	It("aggregates the rows", func() {
+		rows, err := client.Stats(nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		welcome := rows.ByCategory()["welcome"].Sum()
+		Expect(welcome.Requests).To(Equal(uint64(200)))
+		Expect(welcome.Delivered).To(Equal(uint64(190)))
+		Expect(welcome.DeliveryRate()).To(Equal(0.95))
+		Expect(welcome.BounceRate()).To(Equal(0.05))
+		Expect(welcome.ClickRate()).To(Equal(20.0 / 190))
+		Expect(welcome.UnsubscribeRate()).To(Equal(5.0 / 190))
+
+		Expect(rows.Sum().Requests).To(Equal(uint64(210)))
+		Expect(StatsRows(nil).Sum().OpenRate()).To(BeZero())
+	})
+
+	Context("when the v3 API is used", func() {
+		var marketing *FakeMarketing
+
+		BeforeEach(func() {
+			marketing = NewFakeMarketing()
+			marketing.Stats = []map[string]interface{}{
+				{"date": "2015-06-01", "stats": []map[string]interface{}{
+					{"name": "welcome", "type": "category", "metrics": map[string]interface{}{
+						"requests": 100, "delivered": 90, "bounces": 10, "blocks": 2, "spam_reports": 1}},
+				}},
+			}
+			client = marketing.Client()
+		})
+
+		AfterEach(func() {
+			marketing.Close()
+		})
+
+		It("returns the typed daily rows", func() {
+			rows, err := client.Stats(&StatsQuery{
+				StartDate:  time.Date(2015, 6, 1, 0, 0, 0, 0, time.UTC),
+				EndDate:    time.Date(2015, 6, 2, 0, 0, 0, 0, time.UTC),
+				Categories: []string{"welcome"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rows).To(Equal(StatsRows{{
+				Date:        Date{time.Date(2015, 6, 1, 0, 0, 0, 0, time.UTC)},
+				Category:    "welcome",
+				Requests:    100,
+				Delivered:   90,
+				Bounces:     10,
+				Blocked:     2,
+				SpamReports: 1,
+			}}))
+			Expect(marketing.Requests).To(Equal([]string{"GET /v3/categories/stats"}))
+			Expect(marketing.Query).To(Equal(url.Values{
+				"start_date":    {"2015-06-01"},
+				"end_date":      {"2015-06-02"},
+				"aggregated_by": {"day"},
+				"categories":    {"welcome"},
+			}))
+		})
+	})
+})
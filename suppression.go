@@ -0,0 +1,377 @@
+package sendbit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The layout of the SendGrid timestamps
+const TimestampLayout = "2006-01-02 15:04:05"
+
+// The layout of the SendGrid dates
+const DateLayout = "2006-01-02"
+
+// Represents a SendGrid timestamp such as "2015-06-01 19:41:39"
+type Timestamp struct {
+	time.Time
+}
+
+func (timestamp Timestamp) MarshalJSON() ([]byte, error) {
+	if timestamp.IsZero() {
+		return []byte(`""`), nil
+	}
+	return json.Marshal(timestamp.Format(TimestampLayout))
+}
+
+// Decodes a timestamp sent as text by the v2 API or as the Unix time by
+// the v3 API
+func (timestamp *Timestamp) UnmarshalJSON(body []byte) error {
+	if len(body) > 0 && body[0] >= '0' && body[0] <= '9' {
+		var seconds int64
+		if err := json.Unmarshal(body, &seconds); err != nil {
+			return err
+		}
+		timestamp.Time = time.Unix(seconds, 0).UTC()
+		return nil
+	}
+
+	var text string
+	if err := json.Unmarshal(body, &text); err != nil {
+		return err
+	}
+
+	if text == "" {
+		timestamp.Time = time.Time{}
+		return nil
+	}
+
+	value, err := time.Parse(TimestampLayout, text)
+	if err != nil {
+		return err
+	}
+	timestamp.Time = value
+	return nil
+}
+
+// Filters the suppression records
+type SuppressionFilter struct {
+	// StartDate - Skips the records created before that day
+	StartDate time.Time
+	// EndDate - Skips the records created after that day
+	EndDate time.Time
+	// Email - Returns the records of a particular email only
+	Email string
+	// Limit - The maximum number of records. All of them when it is zero.
+	Limit int
+	// Offset - The number of records to skip
+	Offset int
+}
+
+func (filter *SuppressionFilter) values() url.Values {
+	data := url.Values{}
+	data.Add("date", "1")
+	if filter == nil {
+		return data
+	}
+
+	if !filter.StartDate.IsZero() {
+		data.Add("start_date", filter.StartDate.Format(DateLayout))
+	}
+	if !filter.EndDate.IsZero() {
+		data.Add("end_date", filter.EndDate.Format(DateLayout))
+	}
+	if filter.Email != "" {
+		data.Add("email", filter.Email)
+	}
+	if filter.Limit > 0 {
+		data.Add("limit", strconv.Itoa(filter.Limit))
+	}
+	if filter.Offset > 0 {
+		data.Add("offset", strconv.Itoa(filter.Offset))
+	}
+	return data
+}
+
+// Builds the query of the v3 suppression endpoints
+func (filter *SuppressionFilter) query() url.Values {
+	query := url.Values{}
+	if filter == nil {
+		return query
+	}
+
+	if !filter.StartDate.IsZero() {
+		query.Add("start_time", strconv.FormatInt(filter.StartDate.Unix(), 10))
+	}
+	if !filter.EndDate.IsZero() {
+		// The end date is inclusive like the one of the v2 API
+		query.Add("end_time", strconv.FormatInt(filter.EndDate.AddDate(0, 0, 1).Unix()-1, 10))
+	}
+	if filter.Limit > 0 {
+		query.Add("limit", strconv.Itoa(filter.Limit))
+	}
+	if filter.Offset > 0 {
+		query.Add("offset", strconv.Itoa(filter.Offset))
+	}
+	return query
+}
+
+// The v3 paths of the suppression kinds named after their v2 endpoints
+var marketingSuppressions = map[string]string{
+	"bounces":       "/suppression/bounces",
+	"blocks":        "/suppression/blocks",
+	"spamreports":   "/suppression/spam_reports",
+	"invalidemails": "/suppression/invalid_emails",
+	"unsubscribes":  "/suppression/unsubscribes",
+}
+
+// The v3 path of the global unsubscribes
+const globalSuppressions = "/asm/suppressions/global"
+
+// Represents an email that bounced
+type Bounce struct {
+	// The bounced email
+	Email string `json:"email"`
+	// The SMTP status code such as "5.1.1"
+	Status string `json:"status"`
+	// The bounce reason reported by the receiving server
+	Reason string `json:"reason"`
+	// The time the bounce was recorded at
+	Created Timestamp `json:"created"`
+}
+
+// Determines whether the bounce is permanent
+func (bounce *Bounce) Hard() bool {
+	return strings.HasPrefix(bounce.Status, "5")
+}
+
+// Represents an email blocked by the receiving server
+type Block struct {
+	// The blocked email
+	Email string `json:"email"`
+	// The SMTP status code
+	Status string `json:"status"`
+	// The block reason reported by the receiving server
+	Reason string `json:"reason"`
+	// The time the block was recorded at
+	Created Timestamp `json:"created"`
+}
+
+// Represents an email that reported a message as spam
+type SpamReport struct {
+	// The reporting email
+	Email string `json:"email"`
+	// The IP address the message was sent from
+	IP string `json:"ip"`
+	// The time the report was recorded at
+	Created Timestamp `json:"created"`
+}
+
+// Represents an email that does not exist or is malformed
+type InvalidEmail struct {
+	// The invalid email
+	Email string `json:"email"`
+	// The reason the email is invalid
+	Reason string `json:"reason"`
+	// The time the email was recorded at
+	Created Timestamp `json:"created"`
+}
+
+// List the bounced emails
+func (client *Client) Bounces(filter *SuppressionFilter) ([]Bounce, error) {
+	return client.BouncePager(filter).All(client.context())
+}
+
+// Remove an email from the bounces
+func (client *Client) DeleteBounce(email string) error {
+	ctx, span := client.startSpan("DeleteBounce")
+	defer span.End()
+
+	if err := client.deleteSuppression(ctx, "bounces", email); err != nil {
+		return client.errorf("DeleteBounce", err)
+	}
+	return nil
+}
+
+// List the blocked emails
+func (client *Client) Blocks(filter *SuppressionFilter) ([]Block, error) {
+	return client.BlockPager(filter).All(client.context())
+}
+
+// Remove an email from the blocks
+func (client *Client) DeleteBlock(email string) error {
+	ctx, span := client.startSpan("DeleteBlock")
+	defer span.End()
+
+	if err := client.deleteSuppression(ctx, "blocks", email); err != nil {
+		return client.errorf("DeleteBlock", err)
+	}
+	return nil
+}
+
+// List the emails that reported messages as spam
+func (client *Client) SpamReports(filter *SuppressionFilter) ([]SpamReport, error) {
+	return client.SpamReportPager(filter).All(client.context())
+}
+
+// Remove an email from the spam reports
+func (client *Client) DeleteSpamReport(email string) error {
+	ctx, span := client.startSpan("DeleteSpamReport")
+	defer span.End()
+
+	if err := client.deleteSuppression(ctx, "spamreports", email); err != nil {
+		return client.errorf("DeleteSpamReport", err)
+	}
+	return nil
+}
+
+// List the invalid emails
+func (client *Client) InvalidEmails(filter *SuppressionFilter) ([]InvalidEmail, error) {
+	return client.InvalidEmailPager(filter).All(client.context())
+}
+
+// Remove an email from the invalid emails
+func (client *Client) DeleteInvalidEmail(email string) error {
+	ctx, span := client.startSpan("DeleteInvalidEmail")
+	defer span.End()
+
+	if err := client.deleteSuppression(ctx, "invalidemails", email); err != nil {
+		return client.errorf("DeleteInvalidEmail", err)
+	}
+	return nil
+}
+
+// Page through the bounced emails. The pages start at the filter offset
+// and stop at the filter limit.
+func (client *Client) BouncePager(filter *SuppressionFilter) *Pager[Bounce] {
+	return suppressionPager[Bounce](client, "Bounces", "bounces", filter)
+}
+
+// Page through the blocked emails. The pages start at the filter offset
+// and stop at the filter limit.
+func (client *Client) BlockPager(filter *SuppressionFilter) *Pager[Block] {
+	return suppressionPager[Block](client, "Blocks", "blocks", filter)
+}
+
+// Page through the emails that reported messages as spam. The pages
+// start at the filter offset and stop at the filter limit.
+func (client *Client) SpamReportPager(filter *SuppressionFilter) *Pager[SpamReport] {
+	return suppressionPager[SpamReport](client, "SpamReports", "spamreports", filter)
+}
+
+// Page through the invalid emails. The pages start at the filter offset
+// and stop at the filter limit.
+func (client *Client) InvalidEmailPager(filter *SuppressionFilter) *Pager[InvalidEmail] {
+	return suppressionPager[InvalidEmail](client, "InvalidEmails", "invalidemails", filter)
+}
+
+// Pages through the suppression records using the offset as a cursor
+func suppressionPager[T any](client *Client, method, kind string, filter *SuppressionFilter) *Pager[T] {
+	base := SuppressionFilter{}
+	if filter != nil {
+		base = *filter
+	}
+
+	return newPager(client, method, func(ctx context.Context, cursor string, size int) ([]T, string, error) {
+		page := base
+		if cursor != "" {
+			offset, err := strconv.Atoi(cursor)
+			if err != nil {
+				return nil, "", err
+			}
+			page.Offset = offset
+		}
+
+		page.Limit = size
+		if base.Limit > 0 {
+			page.Limit = min(size, base.Offset+base.Limit-page.Offset)
+		}
+
+		var records []T
+		if err := client.suppressions(ctx, kind, &page, &records); err != nil {
+			return nil, "", err
+		}
+
+		end := page.Offset + len(records)
+		if len(records) < page.Limit || base.Limit > 0 && end >= base.Offset+base.Limit {
+			return records, "", nil
+		}
+		return records, strconv.Itoa(end), nil
+	})
+}
+
+func (client *Client) suppressions(ctx context.Context, kind string, filter *SuppressionFilter, records interface{}) error {
+	if client.API == MarketingAPI {
+		return client.marketingSuppressions(ctx, kind, filter, records)
+	}
+
+	response, err := client.post(ctx, "/"+kind+".get.json", filter.values())
+	if err != nil {
+		return err
+	}
+
+	return json.NewDecoder(response).Decode(records)
+}
+
+func (client *Client) marketingSuppressions(ctx context.Context, kind string, filter *SuppressionFilter, records interface{}) error {
+	path := marketingSuppressions[kind]
+	if filter == nil || filter.Email == "" {
+		return client.call(ctx, http.MethodGet, path+"?"+filter.query().Encode(), nil, records)
+	}
+
+	if kind != "unsubscribes" {
+		path += "/" + url.PathEscape(filter.Email)
+		return client.call(ctx, http.MethodGet, path+"?"+filter.query().Encode(), nil, records)
+	}
+
+	// The global unsubscribes are looked up by email at another endpoint
+	unsubscribed, err := client.globallySuppressed(ctx, filter.Email)
+	if err != nil {
+		return err
+	}
+
+	found := []map[string]string{}
+	if unsubscribed {
+		found = append(found, map[string]string{"email": filter.Email})
+	}
+	body, err := json.Marshal(found)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, records)
+}
+
+// Determines whether an email is globally unsubscribed in the v3 API
+func (client *Client) globallySuppressed(ctx context.Context, email string) (bool, error) {
+	var found struct {
+		Email string `json:"recipient_email"`
+	}
+	if err := client.call(ctx, http.MethodGet, globalSuppressions+"/"+url.PathEscape(email), nil, &found); err != nil {
+		return false, err
+	}
+	return found.Email != "", nil
+}
+
+func (client *Client) deleteSuppression(ctx context.Context, kind, email string) error {
+	if email == "" {
+		return errors.New("The email is empty.")
+	}
+
+	if client.API == MarketingAPI {
+		path := marketingSuppressions[kind]
+		if kind == "unsubscribes" {
+			path = globalSuppressions
+		}
+		return client.call(ctx, http.MethodDelete, path+"/"+url.PathEscape(email), nil, nil)
+	}
+
+	data := url.Values{}
+	data.Add("email", email)
+	_, err := client.post(ctx, "/"+kind+".delete.json", data)
+	return err
+}
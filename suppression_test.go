@@ -0,0 +1,192 @@
+package sendbit_test
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	. "github.com/svett/sendbit"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Suppression", func() {
+	var (
+		fake   *FakeSendGrid
+		client *Client
+	)
+
+	BeforeEach(func() {
+		fake = NewFakeSendGrid()
+		fake.Suppressions["bounces"] = []map[string]string{
+			{"email": "j.smith@example.com", "status": "5.1.1", "reason": "550 No such user", "created": "2015-06-01 19:41:39"},
+			{"email": "m.j@example.com", "status": "4.2.2", "reason": "452 Mailbox full", "created": "2015-06-02 08:00:00"},
+		}
+		fake.Suppressions["blocks"] = []map[string]string{
+			{"email": "j.j@example.com", "status": "4.0.0", "reason": "Blocked", "created": "2015-06-03 10:00:00"},
+		}
+		fake.Suppressions["spamreports"] = []map[string]string{
+			{"email": "mike.t@example.com", "ip": "174.36.80.219", "created": "2015-06-04 11:00:00"},
+		}
+		fake.Suppressions["invalidemails"] = []map[string]string{
+			{"email": "nobody@example", "reason": "Mail domain mentioned in email address is unknown", "created": "2015-06-05 12:00:00"},
+		}
+		client = fake.Client()
+	})
+
+	AfterEach(func() {
+		fake.Close()
+	})
+
+	It("lists the bounces", func() {
+		bounces, err := client.Bounces(nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(bounces).To(HaveLen(2))
+		Expect(bounces[0]).To(Equal(Bounce{
+			Email:   "j.smith@example.com",
+			Status:  "5.1.1",
+			Reason:  "550 No such user",
+			Created: Timestamp{time.Date(2015, 6, 1, 19, 41, 39, 0, time.UTC)},
+		}))
+		Expect(bounces[0].Hard()).To(BeTrue())
+		Expect(bounces[1].Hard()).To(BeFalse())
+	})
+
+	It("lists every page of the bounces", func() {
+		for index := 0; index < DefaultPageSize; index++ {
+			fake.Suppressions["bounces"] = append(fake.Suppressions["bounces"], map[string]string{
+				"email": fmt.Sprintf("user%d@example.com", index), "status": "5.1.1",
+			})
+		}
+
+		bounces, err := client.Bounces(nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(bounces).To(HaveLen(DefaultPageSize + 2))
+		Expect(fake.Forms).To(HaveLen(2))
+	})
+
+	It("sends the filter", func() {
+		_, err := client.Bounces(&SuppressionFilter{
+			StartDate: time.Date(2015, 6, 1, 0, 0, 0, 0, time.UTC),
+			EndDate:   time.Date(2015, 6, 30, 0, 0, 0, 0, time.UTC),
+			Email:     "m.j@example.com",
+			Limit:     10,
+			Offset:    20,
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fake.Forms[0]).To(Equal(url.Values{
+			"api_user":   {"user"},
+			"api_key":    {"pass"},
+			"date":       {"1"},
+			"start_date": {"2015-06-01"},
+			"end_date":   {"2015-06-30"},
+			"email":      {"m.j@example.com"},
+			"limit":      {"10"},
+			"offset":     {"20"},
+		}))
+	})
+
+	It("lists the blocks, spam reports and invalid emails", func() {
+		blocks, err := client.Blocks(nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(blocks).To(HaveLen(1))
+		Expect(blocks[0].Reason).To(Equal("Blocked"))
+
+		reports, err := client.SpamReports(&SuppressionFilter{Email: "mike.t@example.com"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reports).To(HaveLen(1))
+		Expect(reports[0].IP).To(Equal("174.36.80.219"))
+
+		invalid, err := client.InvalidEmails(nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(invalid).To(HaveLen(1))
+		Expect(invalid[0].Created.Day()).To(Equal(5))
+	})
+
+	It("deletes the suppressions", func() {
+		Expect(client.DeleteBounce("j.smith@example.com")).To(Succeed())
+		Expect(client.DeleteBlock("j.j@example.com")).To(Succeed())
+		Expect(client.DeleteSpamReport("mike.t@example.com")).To(Succeed())
+		Expect(client.DeleteInvalidEmail("nobody@example")).To(Succeed())
+
+		Expect(fake.Suppressions["bounces"]).To(HaveLen(1))
+		Expect(fake.Suppressions["blocks"]).To(BeEmpty())
+		Expect(fake.Suppressions["spamreports"]).To(BeEmpty())
+		Expect(fake.Suppressions["invalidemails"]).To(BeEmpty())
+	})
+
+	Context("when the email is not suppressed", func() {
+		It("fails to delete it", func() {
+			Expect(client.DeleteBounce("nobody@example.com")).To(
+				MatchError("sendbit: client.DeleteBounce error: Email does not exist"))
+		})
+	})
+
+	Context("when the email is empty", func() {
+		It("fails to delete it", func() {
+			Expect(client.DeleteBlock("")).To(
+				MatchError("sendbit: client.DeleteBlock error: The email is empty."))
+		})
+	})
+
+	Context("when the v3 API is used", func() {
+		var marketing *FakeMarketing
+
+		BeforeEach(func() {
+			marketing = NewFakeMarketing()
+			marketing.Suppressions["bounces"] = []map[string]interface{}{
+				{"email": "j.smith@example.com", "status": "5.1.1", "reason": "550 No such user", "created": 1433187699},
+				{"email": "m.j@example.com", "status": "4.2.2", "reason": "452 Mailbox full", "created": 1433232000},
+			}
+			marketing.Suppressions["spam_reports"] = []map[string]interface{}{
+				{"email": "mike.t@example.com", "ip": "174.36.80.219", "created": 1433415600},
+			}
+			client = marketing.Client()
+		})
+
+		AfterEach(func() {
+			marketing.Close()
+		})
+
+		It("lists the suppressions", func() {
+			bounces, err := client.Bounces(nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(bounces).To(HaveLen(2))
+			Expect(bounces[0]).To(Equal(Bounce{
+				Email:   "j.smith@example.com",
+				Status:  "5.1.1",
+				Reason:  "550 No such user",
+				Created: Timestamp{time.Date(2015, 6, 1, 19, 41, 39, 0, time.UTC)},
+			}))
+
+			reports, err := client.SpamReports(&SuppressionFilter{Email: "mike.t@example.com"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(reports).To(HaveLen(1))
+			Expect(marketing.Requests).To(ContainElement("GET /v3/suppression/spam_reports/mike.t@example.com"))
+		})
+
+		It("sends the filter", func() {
+			_, err := client.Bounces(&SuppressionFilter{
+				StartDate: time.Date(2015, 6, 1, 0, 0, 0, 0, time.UTC),
+				EndDate:   time.Date(2015, 6, 30, 0, 0, 0, 0, time.UTC),
+				Limit:     10,
+				Offset:    20,
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(marketing.Query).To(Equal(url.Values{
+				"start_time": {"1433116800"},
+				"end_time":   {"1435708799"},
+				"limit":      {"10"},
+				"offset":     {"20"},
+			}))
+		})
+
+		It("deletes the suppressions", func() {
+			Expect(client.DeleteBounce("j.smith@example.com")).To(Succeed())
+			Expect(client.DeleteSpamReport("mike.t@example.com")).To(Succeed())
+			Expect(marketing.Suppressions["bounces"]).To(HaveLen(1))
+			Expect(marketing.Suppressions["spam_reports"]).To(BeEmpty())
+		})
+	})
+})
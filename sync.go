@@ -0,0 +1,206 @@
+package sendbit
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Configures a list synchronization
+type SyncOptions struct {
+	// DryRun - Computes the plan without applying it
+	DryRun bool
+	// KeepExtra - Keeps the recipients which are not in the desired state
+	KeepExtra bool
+	// BatchSize - The maximum number of recipients per request.
+	// MaxBatchSize is used when it is zero.
+	BatchSize int
+}
+
+// Represents the changes that bring a list to its desired state
+type SyncPlan struct {
+	// The synchronized list
+	List string
+	// The recipients to be added
+	Add []Recipient
+	// The recipients whose name or custom fields change
+	Update []Recipient
+	// The recipients to be removed
+	Remove []Recipient
+}
+
+// Determines whether the list is already in its desired state
+func (plan *SyncPlan) Empty() bool {
+	return len(plan.Add) == 0 && len(plan.Update) == 0 && len(plan.Remove) == 0
+}
+
+// Formats the plan as one line per change followed by a summary
+func (plan *SyncPlan) String() string {
+	buffer := &bytes.Buffer{}
+	for _, recipient := range plan.Add {
+		fmt.Fprintf(buffer, "+ %s\n", recipient.Email)
+	}
+	for _, recipient := range plan.Update {
+		fmt.Fprintf(buffer, "~ %s\n", recipient.Email)
+	}
+	for _, recipient := range plan.Remove {
+		fmt.Fprintf(buffer, "- %s\n", recipient.Email)
+	}
+	fmt.Fprintf(buffer, "Plan for %s: %d to add, %d to change, %d to remove.",
+		plan.List, len(plan.Add), len(plan.Update), len(plan.Remove))
+	return buffer.String()
+}
+
+// Summarizes a list synchronization
+type SyncReport struct {
+	// The computed plan
+	Plan *SyncPlan
+	// Whether the plan was applied
+	Applied bool
+	// The number of the added recipients
+	Added int
+	// The number of the updated recipients
+	Updated int
+	// The number of the removed recipients
+	Removed int
+}
+
+// Brings a list to the desired recipients. The current recipients are
+// compared by email with the desired ones and the plan of additions,
+// updates and removals is applied in batches unless it is a dry run.
+// The added and updated recipients are validated before any change is
+// made. Updates are applied as UpdateRecipient does and are not skipped
+// when Client.SkipUnsubscribed is set, as the recipients already exist.
+func (client *Client) SyncList(list string, desired []Recipient, options *SyncOptions) (*SyncReport, error) {
+	ctx, span := client.startSpan("SyncList")
+	defer span.End()
+
+	errorf := func(err error) error {
+		return client.errorf("SyncList", err)
+	}
+
+	if list == "" {
+		return nil, errorf(errors.New("The list is empty."))
+	}
+
+	if options == nil {
+		options = &SyncOptions{}
+	}
+
+	batchSize := options.BatchSize
+	if batchSize <= 0 || batchSize > MaxBatchSize {
+		batchSize = MaxBatchSize
+	}
+
+	inner := client.WithContext(ctx)
+	current, err := inner.Recipients(list)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &SyncPlan{List: list}
+	existing := make(map[string]*Recipient, len(current))
+	for index := range current {
+		existing[strings.ToLower(current[index].Email)] = &current[index]
+	}
+
+	wanted := make(map[string]bool, len(desired))
+	for index := range desired {
+		recipient, err := client.normalizeRecipient(&desired[index])
+		if err != nil {
+			return nil, errorf(err)
+		}
+
+		key := strings.ToLower(recipient.Email)
+		if wanted[key] {
+			continue
+		}
+		wanted[key] = true
+
+		if found, ok := existing[key]; !ok {
+			plan.Add = append(plan.Add, *recipient)
+		} else if !sameRecipient(found, recipient) {
+			recipient.Email = found.Email
+			plan.Update = append(plan.Update, *recipient)
+		}
+	}
+
+	if !options.KeepExtra {
+		for _, recipient := range current {
+			if !wanted[strings.ToLower(recipient.Email)] {
+				plan.Remove = append(plan.Remove, recipient)
+			}
+		}
+	}
+
+	report := &SyncReport{Plan: plan}
+	if options.DryRun || plan.Empty() {
+		return report, nil
+	}
+
+	backend := client.backend()
+	changed := append(append([]Recipient{}, plan.Add...), plan.Update...)
+	if err := backend.checkRecipients(ctx, changed); err != nil {
+		return report, errorf(err)
+	}
+	report.Applied = true
+
+	for start := 0; start < len(plan.Remove); start += batchSize {
+		batch := plan.Remove[start:min(start+batchSize, len(plan.Remove))]
+		removed, err := inner.DeleteRecipients(list, emails(batch))
+		report.Removed += removed
+		if err != nil {
+			return report, err
+		}
+	}
+
+	var jobs []*Job
+	for index := range plan.Update {
+		recipient := &plan.Update[index]
+		previous := existing[strings.ToLower(recipient.Email)]
+		job, err := backend.updateRecipient(ctx, list, previous, recipient)
+		if err != nil {
+			return report, errorf(err)
+		}
+		if job != nil {
+			jobs = append(jobs, job)
+		}
+		report.Updated++
+	}
+
+	if err := WaitJobs(ctx, jobs); err != nil {
+		return report, errorf(err)
+	}
+
+	for start := 0; start < len(plan.Add); start += batchSize {
+		batch := plan.Add[start:min(start+batchSize, len(plan.Add))]
+		result, err := inner.AddRecipients(list, batch)
+		report.Added += result.Inserted
+		if err != nil {
+			return report, err
+		}
+	}
+
+	return report, nil
+}
+
+func sameRecipient(current, desired *Recipient) bool {
+	if current.Name != desired.Name || len(current.Fields) != len(desired.Fields) {
+		return false
+	}
+	for name, value := range desired.Fields {
+		if actual, ok := current.Fields[name]; !ok || actual != value {
+			return false
+		}
+	}
+	return true
+}
+
+func emails(recipients []Recipient) []string {
+	addresses := make([]string, len(recipients))
+	for index, recipient := range recipients {
+		addresses[index] = recipient.Email
+	}
+	return addresses
+}
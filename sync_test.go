@@ -0,0 +1,122 @@
+package sendbit_test
+
+import (
+	. "github.com/svett/sendbit"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SyncList", func() {
+	var (
+		fake    *FakeSendGrid
+		client  *Client
+		desired []Recipient
+	)
+
+	BeforeEach(func() {
+		fake = NewFakeSendGrid()
+		fake.AddList("newsletter",
+			Recipient{Name: "John Smith", Email: "j.smith@example.com"},
+			Recipient{Name: "Mike T.", Email: "mike.t@example.com"},
+			Recipient{Name: "Old Timer", Email: "old@example.com"},
+		)
+		client = fake.Client()
+
+		desired = []Recipient{
+			{Name: "John Smith", Email: "j.smith@EXAMPLE.com"},
+			{Name: "Mike Tyson", Email: "mike.t@example.com"},
+			{Name: "Morgan Freeman", Email: "m.freeman@example.com"},
+		}
+	})
+
+	AfterEach(func() {
+		fake.Close()
+	})
+
+	It("applies the plan", func() {
+		report, err := client.SyncList("newsletter", desired, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Applied).To(BeTrue())
+		Expect(report.Added).To(Equal(1))
+		Expect(report.Updated).To(Equal(1))
+		Expect(report.Removed).To(Equal(1))
+
+		Expect(fake.Recipients("newsletter")).To(ConsistOf(
+			Recipient{Name: "John Smith", Email: "j.smith@example.com"},
+			Recipient{Name: "Mike Tyson", Email: "mike.t@example.com"},
+			Recipient{Name: "Morgan Freeman", Email: "m.freeman@example.com"},
+		))
+	})
+
+	Context("when it is a dry run", func() {
+		It("reports the plan without applying it", func() {
+			report, err := client.SyncList("newsletter", desired, &SyncOptions{DryRun: true})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(report.Applied).To(BeFalse())
+			Expect(report.Plan.String()).To(Equal("+ m.freeman@example.com\n" +
+				"~ mike.t@example.com\n" +
+				"- old@example.com\n" +
+				"Plan for newsletter: 1 to add, 1 to change, 1 to remove."))
+			Expect(fake.Requests).To(Equal([]string{"/newsletter/lists/email/get.json"}))
+		})
+	})
+
+	Context("when the extra recipients are kept", func() {
+		It("does not remove them", func() {
+			report, err := client.SyncList("newsletter", desired, &SyncOptions{KeepExtra: true})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(report.Plan.Remove).To(BeEmpty())
+			Expect(fake.Recipients("newsletter")).To(HaveLen(4))
+		})
+	})
+
+	Context("when the batch size is set", func() {
+		It("applies the plan in batches", func() {
+			fake.AddList("empty")
+			_, err := client.SyncList("empty", desired, &SyncOptions{BatchSize: 2})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fake.Requests).To(Equal([]string{
+				"/newsletter/lists/email/get.json",
+				"/newsletter/lists/email/add.json",
+				"/newsletter/lists/email/add.json",
+			}))
+		})
+	})
+
+	Context("when the list is in the desired state", func() {
+		It("does not apply anything", func() {
+			report, err := client.SyncList("newsletter", fake.Recipients("newsletter"), nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(report.Plan.Empty()).To(BeTrue())
+			Expect(report.Applied).To(BeFalse())
+		})
+	})
+
+	Context("when an existing recipient is unsubscribed", func() {
+		It("updates the recipient", func() {
+			client.SkipUnsubscribed = true
+			fake.Unsubscribes = []string{"mike.t@example.com"}
+
+			report, err := client.SyncList("newsletter", desired, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(report.Updated).To(Equal(1))
+			Expect(fake.Recipients("newsletter")).To(ContainElement(
+				Recipient{Name: "Mike Tyson", Email: "mike.t@example.com"},
+			))
+		})
+	})
+
+	Context("when a desired email is invalid", func() {
+		It("fails to synchronize", func() {
+			desired = append(desired, Recipient{Email: "nobody"})
+			_, err := client.SyncList("newsletter", desired, nil)
+			Expect(IsInvalidEmail(err)).To(BeTrue())
+			Expect(fake.Recipients("newsletter")).To(ConsistOf(
+				Recipient{Name: "John Smith", Email: "j.smith@example.com"},
+				Recipient{Name: "Mike T.", Email: "mike.t@example.com"},
+				Recipient{Name: "Old Timer", Email: "old@example.com"},
+			))
+		})
+	})
+})
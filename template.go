@@ -0,0 +1,374 @@
+package sendbit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// The maximum page size of the v3 template endpoint
+const maxTemplatePageSize = 200
+
+// Represents a v3 dynamic transactional template
+type Template struct {
+	// The template identificator
+	ID string `json:"id"`
+	// The template name
+	Name string `json:"name"`
+	// The template generation, always "dynamic"
+	Generation string `json:"generation"`
+	// The time the template was updated at
+	UpdatedAt Timestamp `json:"updated_at"`
+	// The template versions
+	Versions []TemplateVersion `json:"versions"`
+}
+
+// Returns the active version of the template or nil
+func (template *Template) ActiveVersion() *TemplateVersion {
+	for index := range template.Versions {
+		if template.Versions[index].Active {
+			return &template.Versions[index]
+		}
+	}
+	return nil
+}
+
+// Represents a version of a dynamic template. At most one version of
+// a template is active and it is the one rendered by Send.
+type TemplateVersion struct {
+	// The version identificator
+	ID string `json:"id"`
+	// The template identificator
+	TemplateID string `json:"template_id"`
+	// The version name
+	Name string `json:"name"`
+	// The subject line. It may contain handlebars expressions.
+	Subject string `json:"subject"`
+	// The HTML content
+	HTML string `json:"html_content"`
+	// The plain text content
+	Text string `json:"plain_content"`
+	// Whether the version is the active one
+	Active bool `json:"-"`
+	// The time the version was updated at
+	UpdatedAt Timestamp `json:"updated_at"`
+}
+
+// Decodes a version whose active flag is sent as 0 or 1
+func (version *TemplateVersion) UnmarshalJSON(body []byte) error {
+	type plain TemplateVersion
+	value := struct {
+		*plain
+		Active int `json:"active"`
+	}{plain: (*plain)(version)}
+
+	if err := json.Unmarshal(body, &value); err != nil {
+		return err
+	}
+	version.Active = value.Active == 1
+	return nil
+}
+
+// Builds the request body without the active flag, which is changed by
+// ActivateTemplateVersion only
+func (version *TemplateVersion) body() map[string]interface{} {
+	return map[string]interface{}{
+		"name":          version.Name,
+		"subject":       version.Subject,
+		"html_content":  version.HTML,
+		"plain_content": version.Text,
+	}
+}
+
+// List the v3 dynamic templates on your account
+func (client *Client) Templates() ([]Template, error) {
+	return client.TemplatePager().All(client.context())
+}
+
+// Page through the v3 dynamic templates on your account
+func (client *Client) TemplatePager() *Pager[Template] {
+	return newPager(client, "Templates", func(ctx context.Context, cursor string, size int) ([]Template, string, error) {
+		if err := client.requireMarketing(); err != nil {
+			return nil, "", err
+		}
+
+		query := pageQuery(cursor, min(size, maxTemplatePageSize))
+		query.Set("generations", "dynamic")
+
+		var page struct {
+			Result   []Template   `json:"result"`
+			Metadata pageMetadata `json:"_metadata"`
+		}
+		if err := client.call(ctx, http.MethodGet, "/templates?"+query.Encode(), nil, &page); err != nil {
+			return nil, "", err
+		}
+
+		next, err := page.Metadata.cursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		return page.Result, next, nil
+	})
+}
+
+// Get a v3 dynamic template with its versions
+func (client *Client) Template(id string) (*Template, error) {
+	ctx, span := client.startSpan("Template")
+	defer span.End()
+
+	errorf := func(err error) error {
+		return client.errorf("Template", err)
+	}
+
+	if err := client.requireMarketing(); err != nil {
+		return nil, errorf(err)
+	}
+
+	if id == "" {
+		return nil, errorf(errors.New("The template id is empty."))
+	}
+
+	template := &Template{}
+	if err := client.call(ctx, http.MethodGet, "/templates/"+url.PathEscape(id), nil, template); err != nil {
+		return nil, errorf(err)
+	}
+
+	return template, nil
+}
+
+// Create a v3 dynamic template
+func (client *Client) CreateTemplate(name string) (*Template, error) {
+	ctx, span := client.startSpan("CreateTemplate")
+	defer span.End()
+
+	errorf := func(err error) error {
+		return client.errorf("CreateTemplate", err)
+	}
+
+	if err := client.requireMarketing(); err != nil {
+		return nil, errorf(err)
+	}
+
+	if name == "" {
+		return nil, errorf(errors.New("The template name is empty."))
+	}
+
+	body := map[string]string{"name": name, "generation": "dynamic"}
+	template := &Template{}
+	if err := client.call(ctx, http.MethodPost, "/templates", body, template); err != nil {
+		return nil, errorf(err)
+	}
+
+	return template, nil
+}
+
+// Rename a v3 dynamic template
+func (client *Client) RenameTemplate(id, name string) error {
+	ctx, span := client.startSpan("RenameTemplate")
+	defer span.End()
+
+	errorf := func(err error) error {
+		return client.errorf("RenameTemplate", err)
+	}
+
+	if err := client.requireMarketing(); err != nil {
+		return errorf(err)
+	}
+
+	if id == "" {
+		return errorf(errors.New("The template id is empty."))
+	}
+
+	if name == "" {
+		return errorf(errors.New("The template name is empty."))
+	}
+
+	body := map[string]string{"name": name}
+	if err := client.call(ctx, http.MethodPatch, "/templates/"+url.PathEscape(id), body, nil); err != nil {
+		return errorf(err)
+	}
+
+	return nil
+}
+
+// Remove a v3 dynamic template with its versions
+func (client *Client) DeleteTemplate(id string) error {
+	ctx, span := client.startSpan("DeleteTemplate")
+	defer span.End()
+
+	errorf := func(err error) error {
+		return client.errorf("DeleteTemplate", err)
+	}
+
+	if err := client.requireMarketing(); err != nil {
+		return errorf(err)
+	}
+
+	if id == "" {
+		return errorf(errors.New("The template id is empty."))
+	}
+
+	if err := client.call(ctx, http.MethodDelete, "/templates/"+url.PathEscape(id), nil, nil); err != nil {
+		return errorf(err)
+	}
+
+	return nil
+}
+
+// Get a version of a v3 dynamic template
+func (client *Client) TemplateVersion(templateID, id string) (*TemplateVersion, error) {
+	ctx, span := client.startSpan("TemplateVersion")
+	defer span.End()
+
+	errorf := func(err error) error {
+		return client.errorf("TemplateVersion", err)
+	}
+
+	if err := client.requireMarketing(); err != nil {
+		return nil, errorf(err)
+	}
+
+	path, err := versionPath(templateID, id)
+	if err != nil {
+		return nil, errorf(err)
+	}
+
+	version := &TemplateVersion{}
+	if err := client.call(ctx, http.MethodGet, path, nil, version); err != nil {
+		return nil, errorf(err)
+	}
+
+	return version, nil
+}
+
+// Add a version to a v3 dynamic template. The version becomes the active
+// one when its Active flag is set.
+func (client *Client) CreateTemplateVersion(templateID string, version *TemplateVersion) (*TemplateVersion, error) {
+	ctx, span := client.startSpan("CreateTemplateVersion")
+	defer span.End()
+
+	errorf := func(err error) error {
+		return client.errorf("CreateTemplateVersion", err)
+	}
+
+	if err := client.requireMarketing(); err != nil {
+		return nil, errorf(err)
+	}
+
+	if templateID == "" {
+		return nil, errorf(errors.New("The template id is empty."))
+	}
+
+	if version == nil || version.Name == "" {
+		return nil, errorf(errors.New("The version name is empty."))
+	}
+
+	body := version.body()
+	if version.Active {
+		body["active"] = 1
+	}
+
+	created := &TemplateVersion{}
+	path := fmt.Sprintf("/templates/%s/versions", url.PathEscape(templateID))
+	if err := client.call(ctx, http.MethodPost, path, body, created); err != nil {
+		return nil, errorf(err)
+	}
+
+	return created, nil
+}
+
+// Change the name, the subject and the content of a template version. The
+// Active flag is ignored, so the active version stays active.
+func (client *Client) UpdateTemplateVersion(version *TemplateVersion) (*TemplateVersion, error) {
+	ctx, span := client.startSpan("UpdateTemplateVersion")
+	defer span.End()
+
+	errorf := func(err error) error {
+		return client.errorf("UpdateTemplateVersion", err)
+	}
+
+	if err := client.requireMarketing(); err != nil {
+		return nil, errorf(err)
+	}
+
+	if version == nil {
+		return nil, errorf(errors.New("The version is nil."))
+	}
+
+	path, err := versionPath(version.TemplateID, version.ID)
+	if err != nil {
+		return nil, errorf(err)
+	}
+
+	updated := &TemplateVersion{}
+	if err := client.call(ctx, http.MethodPatch, path, version.body(), updated); err != nil {
+		return nil, errorf(err)
+	}
+
+	return updated, nil
+}
+
+// Remove a version of a v3 dynamic template
+func (client *Client) DeleteTemplateVersion(templateID, id string) error {
+	ctx, span := client.startSpan("DeleteTemplateVersion")
+	defer span.End()
+
+	errorf := func(err error) error {
+		return client.errorf("DeleteTemplateVersion", err)
+	}
+
+	if err := client.requireMarketing(); err != nil {
+		return errorf(err)
+	}
+
+	path, err := versionPath(templateID, id)
+	if err != nil {
+		return errorf(err)
+	}
+
+	if err := client.call(ctx, http.MethodDelete, path, nil, nil); err != nil {
+		return errorf(err)
+	}
+
+	return nil
+}
+
+// Make a version the active one of its template. The previously active
+// version is deactivated.
+func (client *Client) ActivateTemplateVersion(templateID, id string) (*TemplateVersion, error) {
+	ctx, span := client.startSpan("ActivateTemplateVersion")
+	defer span.End()
+
+	errorf := func(err error) error {
+		return client.errorf("ActivateTemplateVersion", err)
+	}
+
+	if err := client.requireMarketing(); err != nil {
+		return nil, errorf(err)
+	}
+
+	path, err := versionPath(templateID, id)
+	if err != nil {
+		return nil, errorf(err)
+	}
+
+	version := &TemplateVersion{}
+	if err := client.call(ctx, http.MethodPost, path+"/activate", nil, version); err != nil {
+		return nil, errorf(err)
+	}
+
+	return version, nil
+}
+
+func versionPath(templateID, id string) (string, error) {
+	if templateID == "" {
+		return "", errors.New("The template id is empty.")
+	}
+	if id == "" {
+		return "", errors.New("The version id is empty.")
+	}
+	return fmt.Sprintf("/templates/%s/versions/%s", url.PathEscape(templateID), url.PathEscape(id)), nil
+}
@@ -0,0 +1,103 @@
+package sendbit_test
+
+import (
+	. "github.com/svett/sendbit"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Template", func() {
+	var (
+		fake   *FakeMarketing
+		client *Client
+	)
+
+	BeforeEach(func() {
+		fake = NewFakeMarketing()
+		client = fake.Client()
+	})
+
+	AfterEach(func() {
+		fake.Close()
+	})
+
+	It("manages the templates", func() {
+		template, err := client.CreateTemplate("welcome")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(template.Generation).To(Equal("dynamic"))
+
+		Expect(client.RenameTemplate(template.ID, "onboarding")).To(Succeed())
+
+		templates, err := client.Templates()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(templates).To(HaveLen(1))
+		Expect(templates[0].Name).To(Equal("onboarding"))
+
+		Expect(client.DeleteTemplate(template.ID)).To(Succeed())
+		Expect(fake.Templates).To(BeEmpty())
+	})
+
+	It("manages the template versions", func() {
+		template, err := client.CreateTemplate("welcome")
+		Expect(err).ToNot(HaveOccurred())
+
+		first, err := client.CreateTemplateVersion(template.ID, &TemplateVersion{
+			Name:    "v1",
+			Subject: "Welcome {{name}}",
+			HTML:    "<p>Hi {{name}}</p>",
+			Active:  true,
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(first.Active).To(BeTrue())
+		Expect(first.TemplateID).To(Equal(template.ID))
+
+		second, err := client.CreateTemplateVersion(template.ID, &TemplateVersion{Name: "v2", Subject: "Hello"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(second.Active).To(BeFalse())
+
+		second.Subject = "Hello {{name}}"
+		second, err = client.UpdateTemplateVersion(second)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(second.Subject).To(Equal("Hello {{name}}"))
+
+		activated, err := client.ActivateTemplateVersion(template.ID, second.ID)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(activated.Active).To(BeTrue())
+
+		template, err = client.Template(template.ID)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(template.ActiveVersion().ID).To(Equal(second.ID))
+
+		first.Active = false
+		first.Subject = "Welcome back"
+		_, err = client.UpdateTemplateVersion(first)
+		Expect(err).ToNot(HaveOccurred())
+		template, err = client.Template(template.ID)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(template.ActiveVersion().ID).To(Equal(second.ID))
+
+		second.Active = false
+		_, err = client.UpdateTemplateVersion(second)
+		Expect(err).ToNot(HaveOccurred())
+		template, err = client.Template(template.ID)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(template.ActiveVersion().ID).To(Equal(second.ID))
+
+		Expect(client.DeleteTemplateVersion(template.ID, first.ID)).To(Succeed())
+		version, err := client.TemplateVersion(template.ID, second.ID)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(version.Name).To(Equal("v2"))
+
+		_, err = client.TemplateVersion(template.ID, first.ID)
+		Expect(err).To(MatchError("sendbit: client.TemplateVersion error: version not found"))
+	})
+
+	Context("when the version id is empty", func() {
+		It("fails to activate it", func() {
+			_, err := client.ActivateTemplateVersion("d-1", "")
+			Expect(err).To(MatchError("sendbit: client.ActivateTemplateVersion error: The version id is empty."))
+			Expect(fake.Requests).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,43 @@
+package sendbit
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// The instrumentation name reported by the sendbit spans
+const TracerName = "github.com/svett/sendbit"
+
+const (
+	methodKey        = attribute.Key("sendbit.method")
+	endpointKey      = attribute.Key("sendbit.endpoint")
+	responseErrorKey = attribute.Key("sendbit.error")
+	statusCodeKey    = attribute.Key("http.response.status_code")
+)
+
+func (client *Client) tracer() trace.Tracer {
+	provider := client.TracerProvider
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	return provider.Tracer(TracerName)
+}
+
+// Opens a span for a particular client method. The span is a child of
+// the context that the client is bound to.
+func (client *Client) startSpan(method string) (context.Context, trace.Span) {
+	return client.tracer().Start(client.context(), "sendbit.Client."+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(methodKey.String(method)))
+}
+
+// Marks the span of the context as failed
+func failSpan(ctx context.Context, err error) {
+	span := trace.SpanFromContext(ctx)
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
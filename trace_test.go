@@ -0,0 +1,116 @@
+package sendbit_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/svett/sendbit"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Tracing", func() {
+	var (
+		server   *httptest.Server
+		exporter *tracetest.InMemoryExporter
+		provider *sdktrace.TracerProvider
+		client   *Client
+		status   int
+		body     string
+	)
+
+	attributes := func(span tracetest.SpanStub) map[attribute.Key]attribute.Value {
+		values := map[attribute.Key]attribute.Value{}
+		for _, attr := range span.Attributes {
+			values[attr.Key] = attr.Value
+		}
+		return values
+	}
+
+	BeforeEach(func() {
+		status = http.StatusOK
+		body = `{"message": "success"}`
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+			fmt.Fprint(w, body)
+		}))
+
+		exporter = tracetest.NewInMemoryExporter()
+		provider = sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+		var err error
+		client, err = NewClient("user", "pass")
+		Expect(err).ToNot(HaveOccurred())
+		client.Host = server.URL
+		client.TracerProvider = provider
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("opens a span per client call", func() {
+		Expect(client.CreateList("newsletter")).To(Succeed())
+
+		spans := exporter.GetSpans()
+		Expect(spans).To(HaveLen(1))
+		Expect(spans[0].Name).To(Equal("sendbit.Client.CreateList"))
+		Expect(spans[0].Status.Code).To(Equal(codes.Unset))
+
+		values := attributes(spans[0])
+		Expect(values["sendbit.method"].AsString()).To(Equal("CreateList"))
+		Expect(values["sendbit.endpoint"].AsString()).To(Equal("/api/newsletter/lists/add.json"))
+		Expect(values["http.response.status_code"].AsInt64()).To(Equal(int64(http.StatusOK)))
+	})
+
+	It("uses the bound context as a parent", func() {
+		ctx, parent := provider.Tracer("test").Start(context.Background(), "parent")
+		Expect(client.WithContext(ctx).DeleteList("newsletter")).To(Succeed())
+		parent.End()
+
+		spans := exporter.GetSpans()
+		Expect(spans).To(HaveLen(2))
+		Expect(spans[0].Parent.SpanID()).To(Equal(parent.SpanContext().SpanID()))
+		Expect(spans[0].SpanContext.TraceID()).To(Equal(parent.SpanContext().TraceID()))
+	})
+
+	Context("when SendGrid responds with an error", func() {
+		BeforeEach(func() {
+			status = http.StatusBadRequest
+			body = `{"error": "the title(s) 'newsletter' do not exist"}`
+		})
+
+		It("marks the span as failed", func() {
+			_, err := client.List("newsletter")
+			Expect(err).To(HaveOccurred())
+
+			spans := exporter.GetSpans()
+			Expect(spans).To(HaveLen(1))
+			Expect(spans[0].Status.Code).To(Equal(codes.Error))
+			Expect(spans[0].Events).ToNot(BeEmpty())
+
+			values := attributes(spans[0])
+			Expect(values["sendbit.error"].AsString()).To(Equal("the title(s) 'newsletter' do not exist"))
+			Expect(values["http.response.status_code"].AsInt64()).To(Equal(int64(http.StatusBadRequest)))
+		})
+	})
+
+	Context("when the credentials are missing", func() {
+		It("marks the span as failed", func() {
+			client.Auth = nil
+			Expect(client.CreateList("newsletter")).ToNot(Succeed())
+
+			spans := exporter.GetSpans()
+			Expect(spans).To(HaveLen(1))
+			Expect(spans[0].Status.Code).To(Equal(codes.Error))
+		})
+	})
+})
@@ -0,0 +1,133 @@
+package sendbit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Represents a globally unsubscribed email
+type Unsubscribe struct {
+	// The unsubscribed email
+	Email string `json:"email"`
+	// The time the email unsubscribed at
+	Created Timestamp `json:"created"`
+}
+
+// List the globally unsubscribed emails
+func (client *Client) Unsubscribes(filter *SuppressionFilter) ([]Unsubscribe, error) {
+	return client.UnsubscribePager(filter).All(client.context())
+}
+
+// Page through the globally unsubscribed emails. The pages start at the
+// filter offset and stop at the filter limit.
+func (client *Client) UnsubscribePager(filter *SuppressionFilter) *Pager[Unsubscribe] {
+	return suppressionPager[Unsubscribe](client, "Unsubscribes", "unsubscribes", filter)
+}
+
+// Unsubscribe one or more emails globally. The emails are normalized
+// without the role and disposable checks of Client.Validator.
+func (client *Client) AddUnsubscribe(emails ...string) error {
+	ctx, span := client.startSpan("AddUnsubscribe")
+	defer span.End()
+
+	errorf := func(err error) error {
+		return client.errorf("AddUnsubscribe", err)
+	}
+
+	if len(emails) == 0 {
+		return errorf(errors.New("The emails are empty."))
+	}
+
+	normalized := make([]string, len(emails))
+	for index, email := range emails {
+		if email == "" {
+			return errorf(errors.New("The email is empty."))
+		}
+
+		var err error
+		if normalized[index], err = normalizeEmail(email); err != nil {
+			return errorf(err)
+		}
+	}
+
+	if client.API == MarketingAPI {
+		body := map[string][]string{"recipient_emails": normalized}
+		if err := client.call(ctx, http.MethodPost, globalSuppressions, body, nil); err != nil {
+			return errorf(err)
+		}
+		return nil
+	}
+
+	for _, email := range normalized {
+		data := url.Values{}
+		data.Add("email", email)
+		if _, err := client.post(ctx, "/unsubscribes.add.json", data); err != nil {
+			return errorf(err)
+		}
+	}
+
+	return nil
+}
+
+// Remove an email from the global unsubscribes. The email is normalized
+// like in AddUnsubscribe.
+func (client *Client) DeleteUnsubscribe(email string) error {
+	ctx, span := client.startSpan("DeleteUnsubscribe")
+	defer span.End()
+
+	errorf := func(err error) error {
+		return client.errorf("DeleteUnsubscribe", err)
+	}
+
+	if email == "" {
+		return errorf(errors.New("The email is empty."))
+	}
+
+	email, err := normalizeEmail(email)
+	if err != nil {
+		return errorf(err)
+	}
+
+	if err := client.deleteSuppression(ctx, "unsubscribes", email); err != nil {
+		return errorf(err)
+	}
+	return nil
+}
+
+// Returns the lowercased emails which are globally unsubscribed. Every
+// email is looked up on its own with at most Client.Concurrency requests
+// at a time, so the whole unsubscribe list is never downloaded.
+func (client *Client) unsubscribed(ctx context.Context, emails ...string) (map[string]bool, error) {
+	found := make([]bool, len(emails))
+	failures := make([]error, len(emails))
+	client.fanOut(len(emails), func(index int) {
+		found[index], failures[index] = client.isUnsubscribed(ctx, emails[index])
+	})
+
+	unsubscribed := map[string]bool{}
+	for index, email := range emails {
+		if failures[index] != nil {
+			return nil, failures[index]
+		}
+		if found[index] {
+			unsubscribed[strings.ToLower(email)] = true
+		}
+	}
+	return unsubscribed, nil
+}
+
+// Determines whether a single email is globally unsubscribed
+func (client *Client) isUnsubscribed(ctx context.Context, email string) (bool, error) {
+	if client.API == MarketingAPI {
+		return client.globallySuppressed(ctx, email)
+	}
+
+	var unsubscribes []Unsubscribe
+	if err := client.suppressions(ctx, "unsubscribes", &SuppressionFilter{Email: email}, &unsubscribes); err != nil {
+		return false, err
+	}
+	return len(unsubscribes) > 0, nil
+}
@@ -0,0 +1,149 @@
+package sendbit_test
+
+import (
+	. "github.com/svett/sendbit"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Unsubscribe", func() {
+	var (
+		fake   *FakeSendGrid
+		client *Client
+	)
+
+	BeforeEach(func() {
+		fake = NewFakeSendGrid()
+		fake.AddList("newsletter")
+		fake.Unsubscribes = []string{"gone@example.com"}
+		client = fake.Client()
+	})
+
+	AfterEach(func() {
+		fake.Close()
+	})
+
+	It("lists the unsubscribes", func() {
+		unsubscribes, err := client.Unsubscribes(nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(unsubscribes).To(HaveLen(1))
+		Expect(unsubscribes[0].Email).To(Equal("gone@example.com"))
+		Expect(unsubscribes[0].Created.Year()).To(Equal(2015))
+	})
+
+	It("adds and deletes unsubscribes", func() {
+		Expect(client.AddUnsubscribe("a@example.com", "b@example.com")).To(Succeed())
+		Expect(client.DeleteUnsubscribe("gone@example.com")).To(Succeed())
+		Expect(fake.Unsubscribes).To(Equal([]string{"a@example.com", "b@example.com"}))
+	})
+
+	It("normalizes the unsubscribes without the validator policies", func() {
+		client.Validator = &EmailValidator{RejectRole: true}
+		Expect(client.AddUnsubscribe("admin@EXAMPLE.com")).To(Succeed())
+		Expect(fake.Unsubscribes).To(ContainElement("admin@example.com"))
+
+		err := client.AddUnsubscribe("admin")
+		Expect(IsInvalidEmail(err)).To(BeTrue())
+
+		Expect(client.DeleteUnsubscribe(" admin@EXAMPLE.com ")).To(Succeed())
+		Expect(fake.Unsubscribes).ToNot(ContainElement("admin@example.com"))
+	})
+
+	Context("when no emails are given", func() {
+		It("fails to add unsubscribes", func() {
+			Expect(client.AddUnsubscribe()).To(
+				MatchError("sendbit: client.AddUnsubscribe error: The emails are empty."))
+		})
+	})
+
+	Context("when the unsubscribed recipients are skipped", func() {
+		BeforeEach(func() {
+			client.SkipUnsubscribed = true
+		})
+
+		It("does not add an unsubscribed recipient", func() {
+			err := client.AddRecipient("newsletter", &Recipient{Email: "gone@EXAMPLE.com"})
+			Expect(IsRecipientUnsubscribed(err)).To(BeTrue())
+			Expect(fake.Recipients("newsletter")).To(BeEmpty())
+		})
+
+		It("returns the skipped recipients of a bulk add", func() {
+			result, err := client.AddRecipients("newsletter", []Recipient{
+				{Email: "j.smith@example.com"},
+				{Email: "gone@example.com"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(Equal(&AddResult{
+				Inserted:     1,
+				Unsubscribed: []string{"gone@example.com"},
+			}))
+			Expect(fake.Recipients("newsletter")).To(Equal([]Recipient{{Email: "j.smith@example.com"}}))
+		})
+
+		It("looks up every email instead of the whole unsubscribe list", func() {
+			_, err := client.AddRecipients("newsletter", []Recipient{
+				{Email: "j.smith@example.com"},
+				{Email: "m.j@example.com"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			var lookups []string
+			for index, path := range fake.Requests {
+				if path == "/unsubscribes.get.json" {
+					lookups = append(lookups, fake.Forms[index].Get("email"))
+				}
+			}
+			Expect(lookups).To(ConsistOf("j.smith@example.com", "m.j@example.com"))
+		})
+	})
+
+	Context("when the unsubscribed recipients are not skipped", func() {
+		It("adds them", func() {
+			Expect(client.AddRecipient("newsletter", &Recipient{Email: "gone@example.com"})).To(Succeed())
+		})
+	})
+
+	Context("when the v3 API is used", func() {
+		var marketing *FakeMarketing
+
+		BeforeEach(func() {
+			marketing = NewFakeMarketing()
+			marketing.AddList("newsletter")
+			marketing.Unsubscribes = []string{"gone@example.com"}
+			client = marketing.Client()
+		})
+
+		AfterEach(func() {
+			marketing.Close()
+		})
+
+		It("lists the unsubscribes", func() {
+			unsubscribes, err := client.Unsubscribes(nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(unsubscribes).To(HaveLen(1))
+			Expect(unsubscribes[0].Email).To(Equal("gone@example.com"))
+			Expect(unsubscribes[0].Created.Year()).To(Equal(2015))
+
+			unsubscribes, err = client.Unsubscribes(&SuppressionFilter{Email: "j.smith@example.com"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(unsubscribes).To(BeEmpty())
+		})
+
+		It("adds and deletes unsubscribes", func() {
+			Expect(client.AddUnsubscribe("a@example.com", "b@example.com")).To(Succeed())
+			Expect(client.DeleteUnsubscribe("gone@example.com")).To(Succeed())
+			Expect(marketing.Unsubscribes).To(Equal([]string{"a@example.com", "b@example.com"}))
+		})
+
+		It("skips the unsubscribed recipients", func() {
+			client.SkipUnsubscribed = true
+			result, err := client.AddRecipients("newsletter", []Recipient{
+				{Email: "j.smith@example.com"},
+				{Email: "gone@example.com"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Unsubscribed).To(Equal([]string{"gone@example.com"}))
+		})
+	})
+})
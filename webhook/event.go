@@ -0,0 +1,155 @@
+package webhook
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// Represents the type of an event
+type EventType string
+
+const (
+	// The message was received and is ready to be delivered
+	Processed EventType = "processed"
+	// The message was dropped without delivery
+	Dropped EventType = "dropped"
+	// The message was accepted by the receiving server
+	Delivered EventType = "delivered"
+	// The receiving server temporarily rejected the message
+	Deferred EventType = "deferred"
+	// The receiving server rejected the message
+	Bounce EventType = "bounce"
+	// The recipient opened the message
+	Open EventType = "open"
+	// The recipient clicked a link in the message
+	Click EventType = "click"
+	// The recipient marked the message as spam
+	SpamReport EventType = "spamreport"
+	// The recipient unsubscribed from all messages
+	Unsubscribe EventType = "unsubscribe"
+	// The recipient unsubscribed from an unsubscribe group
+	GroupUnsubscribe EventType = "group_unsubscribe"
+	// The recipient resubscribed to an unsubscribe group
+	GroupResubscribe EventType = "group_resubscribe"
+)
+
+var knownTypes = map[EventType]bool{
+	Processed:        true,
+	Dropped:          true,
+	Delivered:        true,
+	Deferred:         true,
+	Bounce:           true,
+	Open:             true,
+	Click:            true,
+	SpamReport:       true,
+	Unsubscribe:      true,
+	GroupUnsubscribe: true,
+	GroupResubscribe: true,
+}
+
+// Determines whether the event type is known to the package
+func (eventType EventType) Known() bool {
+	return knownTypes[eventType]
+}
+
+// Represents the categories of a message. SendGrid sends a single
+// category as a string and many categories as an array.
+type Categories []string
+
+func (categories *Categories) UnmarshalJSON(body []byte) error {
+	var category string
+	if err := json.Unmarshal(body, &category); err == nil {
+		*categories = Categories{category}
+		return nil
+	}
+
+	var values []string
+	if err := json.Unmarshal(body, &values); err != nil {
+		return err
+	}
+	*categories = values
+	return nil
+}
+
+// Represents a Unix timestamp in seconds
+type UnixTime struct {
+	time.Time
+}
+
+func (timestamp UnixTime) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.FormatInt(timestamp.Unix(), 10)), nil
+}
+
+func (timestamp *UnixTime) UnmarshalJSON(body []byte) error {
+	var seconds int64
+	if err := json.Unmarshal(body, &seconds); err != nil {
+		return err
+	}
+	timestamp.Time = time.Unix(seconds, 0).UTC()
+	return nil
+}
+
+// Represents a delivery or engagement event posted by SendGrid
+type Event struct {
+	// The event type
+	Type EventType `json:"event"`
+	// The recipient email
+	Email string `json:"email"`
+	// The time the event happened at
+	Timestamp UnixTime `json:"timestamp"`
+	// The unique event identifier used to de-duplicate events
+	ID string `json:"sg_event_id"`
+	// The message identifier
+	MessageID string `json:"sg_message_id"`
+	// The SMTP message identifier
+	SMTPID string `json:"smtp-id"`
+	// The message categories
+	Categories Categories `json:"category"`
+	// The reason of a dropped, bounced or blocked message
+	Reason string `json:"reason"`
+	// The SMTP status code of a bounced message
+	Status string `json:"status"`
+	// The bounce type, "bounce" or "blocked"
+	BounceType string `json:"type"`
+	// The response of the receiving server
+	Response string `json:"response"`
+	// The number of the delivery attempts of a deferred message
+	Attempt string `json:"attempt"`
+	// The clicked URL
+	URL string `json:"url"`
+	// The user agent of an open or a click
+	UserAgent string `json:"useragent"`
+	// The IP address of an open or a click
+	IP string `json:"ip"`
+	// The unsubscribe group of a group (un)subscribe
+	ASMGroupID int `json:"asm_group_id"`
+	// The custom arguments of the message and the fields not listed above
+	Custom map[string]interface{} `json:"-"`
+}
+
+func (event *Event) UnmarshalJSON(body []byte) error {
+	type plain Event
+	if err := json.Unmarshal(body, (*plain)(event)); err != nil {
+		return err
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return err
+	}
+
+	for _, name := range eventFields {
+		delete(fields, name)
+	}
+	if len(fields) > 0 {
+		event.Custom = fields
+	}
+	return nil
+}
+
+var eventFields = []string{
+	"event", "email", "timestamp", "sg_event_id", "sg_message_id", "smtp-id",
+	"category", "reason", "status", "type", "response", "attempt", "url",
+	"useragent", "ip", "asm_group_id",
+}
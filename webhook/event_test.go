@@ -0,0 +1,54 @@
+package webhook_test
+
+import (
+	"encoding/json"
+	"time"
+
+	. "github.com/svett/sendbit/webhook"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Event", func() {
+	It("decodes a bounce", func() {
+		var event Event
+		Expect(json.Unmarshal([]byte(`{
+			"email": "j.smith@example.com",
+			"timestamp": 1433184099,
+			"event": "bounce",
+			"sg_event_id": "6g4ZI7SA-xmRDv57GoPIPw==",
+			"sg_message_id": "14c5d75ce93.dfd.64b469.filter0001.16648.5515E0B88.0",
+			"smtp-id": "<14c5d75ce93.dfd.64b469@ismtpd-555>",
+			"category": "newsletter",
+			"reason": "500 unknown recipient",
+			"status": "5.0.0",
+			"type": "bounce",
+			"plan": "pro"
+		}`), &event)).To(Succeed())
+
+		Expect(event.Type).To(Equal(Bounce))
+		Expect(event.Type.Known()).To(BeTrue())
+		Expect(event.Email).To(Equal("j.smith@example.com"))
+		Expect(event.Timestamp.Time).To(Equal(time.Date(2015, 6, 1, 18, 41, 39, 0, time.UTC)))
+		Expect(event.ID).To(Equal("6g4ZI7SA-xmRDv57GoPIPw=="))
+		Expect(event.Categories).To(Equal(Categories{"newsletter"}))
+		Expect(event.Status).To(Equal("5.0.0"))
+		Expect(event.BounceType).To(Equal("bounce"))
+		Expect(event.Custom).To(Equal(map[string]interface{}{"plan": "pro"}))
+	})
+
+	It("decodes many categories", func() {
+		var event Event
+		Expect(json.Unmarshal([]byte(`{"event": "open", "category": ["a", "b"]}`), &event)).To(Succeed())
+		Expect(event.Categories).To(Equal(Categories{"a", "b"}))
+		Expect(event.Custom).To(BeNil())
+	})
+
+	It("decodes an unknown event type", func() {
+		var event Event
+		Expect(json.Unmarshal([]byte(`{"event": "machine_opened", "email": "a@example.com"}`), &event)).To(Succeed())
+		Expect(event.Type).To(Equal(EventType("machine_opened")))
+		Expect(event.Type.Known()).To(BeFalse())
+	})
+})
@@ -0,0 +1,8 @@
+package webhook
+
+import "time"
+
+// Sets the clock used to check the replay window
+func (verifier *Verifier) SetClock(now func() time.Time) {
+	verifier.now = now
+}
@@ -0,0 +1,80 @@
+// Package webhook receives the SendGrid Event Webhook calls.
+//
+//	handler := webhook.NewHandler(func(ctx context.Context, event *webhook.Event) error {
+//		log.Printf("%s: %s", event.Type, event.Email)
+//		return nil
+//	})
+//	http.Handle("/sendgrid/events", handler)
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// The maximum size of a request body used when Handler.MaxBodySize is zero
+const DefaultMaxBodySize = 10 << 20
+
+// Processes a single event. The events of unknown types are passed to the
+// callback as well and can be recognized by Event.Type.Known.
+type Callback func(ctx context.Context, event *Event) error
+
+// An http.Handler for the SendGrid Event Webhook. It decodes the batch of
+// events and calls the callback for every event in order. It responds with
+// 200 when all events are processed, with 4xx when the request is invalid
+// and with 500 when the callback fails so that SendGrid retries the whole
+// batch later. The callback should therefore be idempotent, for instance
+// by de-duplicating the events by Event.ID.
+type Handler struct {
+	// Callback - Processes every event
+	Callback Callback
+	// MaxBodySize - The maximum size of a request body in bytes.
+	// DefaultMaxBodySize is used when it is zero.
+	MaxBodySize int64
+}
+
+// Creates a new handler calling the callback for every event
+func NewHandler(callback Callback) *Handler {
+	return &Handler{Callback: callback}
+}
+
+func (handler *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	limit := handler.MaxBodySize
+	if limit <= 0 {
+		limit = DefaultMaxBodySize
+	}
+
+	var events []Event
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, limit)).Decode(&events)
+
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+		return
+	case err == io.EOF:
+		http.Error(w, "request body is empty", http.StatusBadRequest)
+		return
+	case err != nil:
+		http.Error(w, "malformed events: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	for index := range events {
+		if err := handler.Callback(r.Context(), &events[index]); err != nil {
+			http.Error(w, "failed to process the events", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
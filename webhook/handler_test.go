@@ -0,0 +1,95 @@
+package webhook_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/svett/sendbit/webhook"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Handler", func() {
+	var (
+		handler  *Handler
+		events   []Event
+		failure  error
+		recorder *httptest.ResponseRecorder
+	)
+
+	post := func(body string) {
+		request := httptest.NewRequest(http.MethodPost, "/events", strings.NewReader(body))
+		recorder = httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+	}
+
+	BeforeEach(func() {
+		events = nil
+		failure = nil
+		handler = NewHandler(func(ctx context.Context, event *Event) error {
+			if failure != nil {
+				return failure
+			}
+			events = append(events, *event)
+			return nil
+		})
+	})
+
+	It("calls the callback for every event", func() {
+		post(`[
+			{"email": "a@example.com", "event": "processed", "timestamp": 1433184099},
+			{"email": "a@example.com", "event": "delivered", "timestamp": 1433184100},
+			{"email": "a@example.com", "event": "machine_opened", "timestamp": 1433184101}
+		]`)
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(events).To(HaveLen(3))
+		Expect(events[0].Type).To(Equal(Processed))
+		Expect(events[1].Type).To(Equal(Delivered))
+		Expect(events[2].Type.Known()).To(BeFalse())
+	})
+
+	Context("when the callback fails", func() {
+		It("responds with 500 so that SendGrid retries", func() {
+			failure = errors.New("database is down")
+			post(`[{"email": "a@example.com", "event": "open"}]`)
+			Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+		})
+	})
+
+	Context("when the body is malformed", func() {
+		It("responds with 400", func() {
+			post(`{"email": "a@example.com"}`)
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(events).To(BeEmpty())
+		})
+	})
+
+	Context("when the body is empty", func() {
+		It("responds with 400", func() {
+			post("")
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Context("when the body is too large", func() {
+		It("responds with 413", func() {
+			handler.MaxBodySize = 16
+			post(`[{"email": "a@example.com", "event": "open"}]`)
+			Expect(recorder.Code).To(Equal(http.StatusRequestEntityTooLarge))
+		})
+	})
+
+	Context("when the method is not POST", func() {
+		It("responds with 405", func() {
+			recorder = httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/events", nil))
+			Expect(recorder.Code).To(Equal(http.StatusMethodNotAllowed))
+			Expect(recorder.Header().Get("Allow")).To(Equal(http.MethodPost))
+		})
+	})
+})
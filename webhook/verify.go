@@ -0,0 +1,150 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// The header carrying the base64 encoded ECDSA signature
+	SignatureHeader = "X-Twilio-Email-Event-Webhook-Signature"
+	// The header carrying the Unix time the request was signed at
+	TimestampHeader = "X-Twilio-Email-Event-Webhook-Timestamp"
+)
+
+// The replay window used when Verifier.Window is zero
+const DefaultWindow = 5 * time.Minute
+
+var (
+	// The signature or the timestamp header is missing
+	ErrMissingSignature = errors.New("webhook: the signature is missing")
+	// The signature does not match the request
+	ErrInvalidSignature = errors.New("webhook: the signature is invalid")
+	// The request was signed outside of the replay window
+	ErrExpiredSignature = errors.New("webhook: the signature is expired")
+)
+
+// Parses a base64 encoded DER public key as shown in the SendGrid
+// Signed Event Webhook settings
+func ParsePublicKey(key string) (*ecdsa.PublicKey, error) {
+	der, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, ok := parsed.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, errors.New("webhook: the public key is not an ECDSA key")
+	}
+	return publicKey, nil
+}
+
+// Verifies that the Event Webhook requests are signed by SendGrid
+type Verifier struct {
+	// PublicKey - The verification key of the account
+	PublicKey *ecdsa.PublicKey
+	// Window - The maximum age of a signature. DefaultWindow is used
+	// when it is zero.
+	Window time.Duration
+	// MaxBodySize - The maximum size of a request body in bytes.
+	// DefaultMaxBodySize is used when it is zero.
+	MaxBodySize int64
+
+	now func() time.Time
+}
+
+// Creates a new verifier from a base64 encoded DER public key
+func NewVerifier(publicKey string) (*Verifier, error) {
+	key, err := ParsePublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Verifier{PublicKey: key}, nil
+}
+
+// Verifies the signature and the timestamp headers of a request body
+func (verifier *Verifier) Verify(signature, timestamp string, body []byte) error {
+	if signature == "" || timestamp == "" {
+		return ErrMissingSignature
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	window := verifier.Window
+	if window <= 0 {
+		window = DefaultWindow
+	}
+
+	now := time.Now
+	if verifier.now != nil {
+		now = verifier.now
+	}
+
+	age := now().Sub(time.Unix(seconds, 0))
+	if age > window || age < -window {
+		return ErrExpiredSignature
+	}
+
+	der, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	digest := sha256.New()
+	digest.Write([]byte(timestamp))
+	digest.Write(body)
+	if !ecdsa.VerifyASN1(verifier.PublicKey, digest.Sum(nil), der) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// Wraps a handler so that it is called for signed requests only. The
+// requests which are not signed, signed with another key or signed
+// outside of the replay window are rejected with 403.
+func (verifier *Verifier) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit := verifier.MaxBodySize
+		if limit <= 0 {
+			limit = DefaultMaxBodySize
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, limit))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		if err != nil {
+			http.Error(w, "failed to read the request body", http.StatusBadRequest)
+			return
+		}
+
+		signature := r.Header.Get(SignatureHeader)
+		timestamp := r.Header.Get(TimestampHeader)
+		if err := verifier.Verify(signature, timestamp, body); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		next.ServeHTTP(w, r)
+	})
+}
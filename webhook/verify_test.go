@@ -0,0 +1,128 @@
+package webhook_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/svett/sendbit/webhook"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Verifier", func() {
+	var (
+		privateKey *ecdsa.PrivateKey
+		verifier   *Verifier
+		now        time.Time
+		body       string
+	)
+
+	sign := func(key *ecdsa.PrivateKey, timestamp, body string) string {
+		digest := sha256.Sum256([]byte(timestamp + body))
+		signature, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+		Expect(err).ToNot(HaveOccurred())
+		return base64.StdEncoding.EncodeToString(signature)
+	}
+
+	BeforeEach(func() {
+		var err error
+		privateKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).ToNot(HaveOccurred())
+
+		der, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+		Expect(err).ToNot(HaveOccurred())
+
+		verifier, err = NewVerifier(base64.StdEncoding.EncodeToString(der))
+		Expect(err).ToNot(HaveOccurred())
+
+		now = time.Unix(1600000000, 0)
+		verifier.SetClock(func() time.Time { return now })
+		body = `[{"email": "a@example.com", "event": "open"}]`
+	})
+
+	It("accepts a valid signature", func() {
+		timestamp := strconv.FormatInt(now.Unix(), 10)
+		Expect(verifier.Verify(sign(privateKey, timestamp, body), timestamp, []byte(body))).To(Succeed())
+	})
+
+	It("rejects a signature of another body", func() {
+		timestamp := strconv.FormatInt(now.Unix(), 10)
+		signature := sign(privateKey, timestamp, body)
+		Expect(verifier.Verify(signature, timestamp, []byte("[]"))).To(MatchError(ErrInvalidSignature))
+	})
+
+	It("rejects a signature of another key", func() {
+		otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).ToNot(HaveOccurred())
+
+		timestamp := strconv.FormatInt(now.Unix(), 10)
+		signature := sign(otherKey, timestamp, body)
+		Expect(verifier.Verify(signature, timestamp, []byte(body))).To(MatchError(ErrInvalidSignature))
+	})
+
+	It("rejects a replayed signature", func() {
+		timestamp := strconv.FormatInt(now.Add(-DefaultWindow-time.Second).Unix(), 10)
+		signature := sign(privateKey, timestamp, body)
+		Expect(verifier.Verify(signature, timestamp, []byte(body))).To(MatchError(ErrExpiredSignature))
+
+		verifier.Window = time.Hour
+		Expect(verifier.Verify(signature, timestamp, []byte(body))).To(Succeed())
+	})
+
+	It("rejects a missing signature", func() {
+		Expect(verifier.Verify("", "1600000000", []byte(body))).To(MatchError(ErrMissingSignature))
+	})
+
+	It("rejects a malformed public key", func() {
+		_, err := NewVerifier("not a key")
+		Expect(err).To(HaveOccurred())
+	})
+
+	Describe("Middleware", func() {
+		var (
+			handler http.Handler
+			events  []Event
+		)
+
+		post := func(signature, timestamp string) *httptest.ResponseRecorder {
+			request := httptest.NewRequest(http.MethodPost, "/events", strings.NewReader(body))
+			request.Header.Set(SignatureHeader, signature)
+			request.Header.Set(TimestampHeader, timestamp)
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+			return recorder
+		}
+
+		BeforeEach(func() {
+			events = nil
+			handler = verifier.Middleware(NewHandler(func(ctx context.Context, event *Event) error {
+				events = append(events, *event)
+				return nil
+			}))
+		})
+
+		It("passes a signed request to the handler", func() {
+			timestamp := strconv.FormatInt(now.Unix(), 10)
+			recorder := post(sign(privateKey, timestamp, body), timestamp)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(events).To(HaveLen(1))
+		})
+
+		It("rejects an unsigned request", func() {
+			recorder := post("", "")
+			Expect(recorder.Code).To(Equal(http.StatusForbidden))
+			Expect(events).To(BeEmpty())
+		})
+	})
+})